@@ -0,0 +1,160 @@
+package mobilewallet
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// defaultConnectTimeout is the per-peer connect timeout used when none has
+// been configured.
+const defaultConnectTimeout = 30 * time.Second
+
+// peerDialer dials SPV peer connections.  It bounds the number of
+// concurrently open peer connections, the time spent connecting to any
+// single peer and the combined read bandwidth of the connections.
+type peerDialer struct {
+	mu          sync.Mutex
+	targetPeers int
+	connected   int
+	timeout     time.Duration
+	limiter     bandwidthLimiter
+
+	// slotFreed is closed and replaced whenever a connection slot may
+	// have become available.
+	slotFreed chan struct{}
+}
+
+func newPeerDialer() *peerDialer {
+	return &peerDialer{
+		timeout:   defaultConnectTimeout,
+		slotFreed: make(chan struct{}),
+	}
+}
+
+// notifySlotFreed wakes dials waiting for a connection slot.  The dialer's
+// mutex must be held.
+func (d *peerDialer) notifySlotFreed() {
+	close(d.slotFreed)
+	d.slotFreed = make(chan struct{})
+}
+
+// setOptions updates the peer target and connect timeout.  A targetPeers of
+// zero leaves the number of peers up to the syncer and a zero timeout
+// restores the default.
+func (d *peerDialer) setOptions(targetPeers int, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	d.mu.Lock()
+	d.targetPeers = targetPeers
+	d.timeout = timeout
+	d.notifySlotFreed()
+	d.mu.Unlock()
+}
+
+// connectTimeout returns the time spent connecting to a single peer.
+func (d *peerDialer) connectTimeout() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.timeout
+}
+
+// acquire waits until a connection slot is available under the peer target
+// and takes it.  Dials are held back rather than failed at the target, since
+// the address manager would record a failed dial against a healthy peer.
+func (d *peerDialer) acquire(ctx context.Context) error {
+	for {
+		d.mu.Lock()
+		if d.targetPeers <= 0 || d.connected < d.targetPeers {
+			d.connected++
+			d.mu.Unlock()
+			return nil
+		}
+		slotFreed := d.slotFreed
+		d.mu.Unlock()
+
+		select {
+		case <-slotFreed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (d *peerDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	err := d.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	timeout := d.timeout
+	d.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		d.release()
+		return nil, err
+	}
+	return &peerConn{Conn: conn, dialer: d}, nil
+}
+
+func (d *peerDialer) release() {
+	d.mu.Lock()
+	d.connected--
+	d.notifySlotFreed()
+	d.mu.Unlock()
+}
+
+// peerConn releases its slot in the dialer when the connection is closed and
+// throttles its reads to the dialer's bandwidth limit.
+type peerConn struct {
+	net.Conn
+	dialer    *peerDialer
+	closeOnce sync.Once
+}
+
+func (c *peerConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.dialer.limiter.wait(n)
+	return n, err
+}
+
+func (c *peerConn) Close() error {
+	c.closeOnce.Do(c.dialer.release)
+	return c.Conn.Close()
+}
+
+// bandwidthLimiter delays reads so that the bytes read by all connections do
+// not exceed a rate in bytes per second.  A rate of zero is unlimited.
+type bandwidthLimiter struct {
+	mu   sync.Mutex
+	rate int64
+	next time.Time
+}
+
+func (l *bandwidthLimiter) setRate(bytesPerSecond int64) {
+	l.mu.Lock()
+	l.rate = bytesPerSecond
+	l.next = time.Time{}
+	l.mu.Unlock()
+}
+
+// wait blocks until reading n bytes keeps the read rate within the limit.
+func (l *bandwidthLimiter) wait(n int) {
+	l.mu.Lock()
+	if l.rate <= 0 || n <= 0 {
+		l.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+	time.Sleep(delay)
+}
@@ -14,7 +14,7 @@ import (
 )
 
 // newHTTPClient returns a new HTTP client that is configured according to the
-//  TLS settings in the associated connection configuration.
+// TLS settings in the associated connection configuration.
 func newHTTPClient(cert string) (*http.Client, error) {
 	var dial func(network, addr string) (net.Conn, error)
 	// Configure TLS
@@ -44,6 +44,42 @@ func newHTTPClient(cert string) (*http.Client, error) {
 // unmarshal the response as a JSON-RPC response and returns either the result
 // field or the error field depending on whether or not there is an error.
 func sendPostRequest(marshalledJSON []byte, rpcServer string, username string, password string, cert string) ([]byte, error) {
+	respBytes, err := postJSON(marshalledJSON, rpcServer, username, password, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the response.
+	var resp dcrjson.Response
+	if err := json.Unmarshal(respBytes, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return resp.Result, nil
+}
+
+// sendBatchPostRequest sends a marshalled batch of JSON-RPC commands like
+// sendPostRequest and returns the JSON-RPC responses to the commands, which
+// are not necessarily in the order of the commands.
+func sendBatchPostRequest(marshalledJSON []byte, rpcServer string, username string, password string, cert string) ([]dcrjson.Response, error) {
+	respBytes, err := postJSON(marshalledJSON, rpcServer, username, password, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	var resps []dcrjson.Response
+	if err := json.Unmarshal(respBytes, &resps); err != nil {
+		return nil, err
+	}
+	return resps, nil
+}
+
+// postJSON posts marshalled JSON to the server and returns the raw response
+// body of a successful HTTP response.
+func postJSON(marshalledJSON []byte, rpcServer string, username string, password string, cert string) ([]byte, error) {
 	// Generate a request to the configured RPC server.
 	protocol := "https"
 	url := protocol + "://" + rpcServer
@@ -93,14 +129,5 @@ func sendPostRequest(marshalledJSON []byte, rpcServer string, username string, p
 	// Print raw json response.
 	fmt.Println(string(respBytes))
 
-	// Unmarshal the response.
-	var resp dcrjson.Response
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
-		return nil, err
-	}
-
-	if resp.Error != nil {
-		return nil, resp.Error
-	}
-	return resp.Result, nil
+	return respBytes, nil
 }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/decred/dcrd/connmgr"
 	dcrrpcclient "github.com/decred/dcrd/rpcclient"
@@ -30,7 +31,9 @@ type logWriter struct{}
 
 func (logWriter) Write(p []byte) (n int, err error) {
 	os.Stdout.Write(p)
+	logRotatorMu.Lock()
 	logRotator.Write(p)
+	logRotatorMu.Unlock()
 	return len(p), nil
 }
 
@@ -52,6 +55,12 @@ var (
 	// application shutdown.
 	logRotator *rotator.Rotator
 
+	// logRotatorMu protects logRotator from being replaced while in use.
+	logRotatorMu sync.Mutex
+
+	// logFilePath is the path of the file written by the log rotator.
+	logFilePath string
+
 	log          = backendLog.Logger("MWLT")
 	loaderLog    = backendLog.Logger("LODR")
 	walletLog    = backendLog.Logger("WLLT")
@@ -99,13 +108,52 @@ func initLogRotator(logFile string) {
 		fmt.Fprintf(os.Stderr, "failed to create log directory: %v\n", err)
 		os.Exit(1)
 	}
-	r, err := rotator.New(logFile, 10*1024, false, 3)
+	r, err := rotator.New(logFile, defaultLogRotatorMaxSizeKB, false, defaultLogRotatorMaxRolls)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create file rotator: %v\n", err)
 		os.Exit(1)
 	}
 
 	logRotator = r
+	logFilePath = logFile
+}
+
+// Default size in KB at which the log file is rolled and number of rolled
+// log files kept.
+const (
+	defaultLogRotatorMaxSizeKB = 10 * 1024
+	defaultLogRotatorMaxRolls  = 3
+)
+
+// setLogRotation replaces the log rotator with one that rolls the log file
+// once it reaches maxSizeKB and keeps at most maxRolls rolled files.  It must
+// only be called after initLogRotator.
+func setLogRotation(maxSizeKB int64, maxRolls int) error {
+	r, err := rotator.New(logFilePath, maxSizeKB, false, maxRolls)
+	if err != nil {
+		return err
+	}
+	logRotatorMu.Lock()
+	old := logRotator
+	logRotator = r
+	logRotatorMu.Unlock()
+	return old.Close()
+}
+
+// purgeLogs removes the rolled log files, leaving the current log file in
+// place.
+func purgeLogs() error {
+	rolled, err := filepath.Glob(logFilePath + ".*")
+	if err != nil {
+		return err
+	}
+	for _, file := range rolled {
+		err := os.Remove(file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // setLogLevel sets the logging level for provided subsystem.  Invalid
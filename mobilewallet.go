@@ -3,25 +3,31 @@ package mobilewallet
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/decred/dcrd/addrmgr"
+	"github.com/decred/dcrd/blockchain"
 	stake "github.com/decred/dcrd/blockchain/stake"
 	"github.com/decred/dcrd/chaincfg"
 	chainhash "github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrjson"
 	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrd/gcs/blockcf"
 	"github.com/decred/dcrd/hdkeychain"
 	"github.com/decred/dcrd/txscript"
 	"github.com/decred/dcrd/wire"
@@ -33,6 +39,7 @@ import (
 	"github.com/decred/dcrwallet/spv"
 	"github.com/decred/dcrwallet/wallet"
 	"github.com/decred/dcrwallet/wallet/txrules"
+	"github.com/decred/dcrwallet/wallet/udb"
 	walletseed "github.com/decred/dcrwallet/walletseed"
 	"github.com/decred/slog"
 )
@@ -53,12 +60,69 @@ type LibWallet struct {
 	activeNet   *netparams.Params
 	chainParams *chaincfg.Params
 	lock        chan time.Time
+	dialer      *peerDialer
+	cancelSync  context.CancelFunc
+	syncState   syncState
+	syncRunning bool
+	syncID      uint64
+
+	backendListener  NetworkBackendListener
+	backendAvailable bool
+	strictSigning    bool
+	publishRetries   int
+	publishBackoff   time.Duration
+	rescanning       bool
+
+	minPassphraseLength int
+	dnsSeedingDisabled  bool
+	dnsSeeds            []string
+}
+
+// syncState records the progress reported by the SPV syncer so that it can be
+// queried after the notifications have fired.  It is protected by the
+// LibWallet mutex.
+type syncState struct {
+	synced          bool
+	targetHeight    int32
+	phase           string
+	phaseStartTime  int64
+	headersProgress int32
+	peerCount       int32
+	rescanHeight    int32
+
+	// rescanStartHeight is the first height reported by the current
+	// rescan, from which rescan progress is measured.
+	rescanStartHeight int32
+}
+
+// Phases of an SPV sync as reported by SyncStatus.
+const (
+	syncPhaseIdle      = "idle"
+	syncPhaseHeaders   = "headers"
+	syncPhaseCFilters  = "cfilters"
+	syncPhaseDiscovery = "discovery"
+	syncPhaseRescan    = "rescan"
+	syncPhaseSynced    = "synced"
+)
+
+// setSyncPhase records the current sync phase, restarting the phase timer
+// used for the time remaining estimate when the phase changes.  The LibWallet
+// mutex must be held.
+func (lw *LibWallet) setSyncPhase(phase string) {
+	if lw.syncState.phase != phase {
+		lw.syncState.phase = phase
+		lw.syncState.phaseStartTime = time.Now().Unix()
+	}
 }
 
 func NewLibWallet(homeDir string, dbDriver string) *LibWallet {
 	lw := &LibWallet{
 		dataDir:  filepath.Join(homeDir, "testnet3/"),
 		dbDriver: dbDriver,
+		dialer:   newPeerDialer(),
+
+		publishRetries: defaultPublishRetries,
+		publishBackoff: defaultPublishBackoff,
 	}
 	errors.Separator = ":: "
 	initLogRotator(filepath.Join(homeDir, "/logs/testnet3/dcrwallet.log"))
@@ -73,6 +137,48 @@ func (lw *LibWallet) SetLogLevel(loglevel string) {
 	}
 }
 
+// SetSPVConnectionOptions limits the number of peers the SPV syncer keeps
+// connected to and the time spent connecting to a single peer.  A
+// targetPeers of 0 leaves the peer count up to the syncer and a
+// connectTimeout of 0 uses the default timeout.  The options apply to
+// connections made after the call.
+//
+// The SPV syncer has no peer count option, so the target is enforced when
+// dialing: once it is reached, the syncer's further connection attempts wait
+// until a connected peer disconnects instead of failing.  Lowering the target
+// does not disconnect peers that are already connected.
+func (lw *LibWallet) SetSPVConnectionOptions(targetPeers int32, connectTimeoutSeconds int32) {
+	lw.dialer.setOptions(int(targetPeers), time.Duration(connectTimeoutSeconds)*time.Second)
+}
+
+// SetMaxSyncBandwidth limits the combined rate at which SPV peer connections
+// read from the network to bytesPerSecond.  A value of 0 removes the limit.
+func (lw *LibWallet) SetMaxSyncBandwidth(bytesPerSecond int64) error {
+	if bytesPerSecond < 0 {
+		return errors.E(errors.Invalid, "bandwidth limit must not be negative")
+	}
+	lw.dialer.limiter.setRate(bytesPerSecond)
+	return nil
+}
+
+// SetLogRotation sets the size in KB at which the log file is rolled and the
+// number of rolled log files that are kept.
+func (lw *LibWallet) SetLogRotation(maxSizeKB int32, maxRolls int32) error {
+	if maxSizeKB <= 0 || maxRolls < 0 {
+		return errors.E(errors.Invalid, "invalid log rotation settings")
+	}
+	return setLogRotation(int64(maxSizeKB), int(maxRolls))
+}
+
+// PurgeLogs deletes the rolled log files to free up storage.
+func (lw *LibWallet) PurgeLogs() error {
+	err := purgeLogs()
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
 func NormalizeAddress(addr string, defaultPort string) (hostport string, err error) {
 	// If the first SplitHostPort errors because of a missing port and not
 	// for an invalid host, add the port.  If the second SplitHostPort
@@ -91,6 +197,7 @@ func NormalizeAddress(addr string, defaultPort string) (hostport string, err err
 }
 
 func (lw *LibWallet) UnlockWallet(privPass []byte) error {
+	defer zeroBytes(privPass)
 	if lw.lock != nil {
 		//Wallet is unlocked
 		return nil
@@ -99,11 +206,6 @@ func (lw *LibWallet) UnlockWallet(privPass []byte) error {
 	if !ok {
 		return fmt.Errorf("Wallet has not been loaded")
 	}
-	defer func() {
-		for i := range privPass {
-			privPass[i] = 0
-		}
-	}()
 	lw.lock = make(chan time.Time, 1)
 	err := wallet.Unlock(privPass, lw.lock)
 	return err
@@ -120,6 +222,9 @@ func (lw *LibWallet) Shutdown() {
 	log.Info("Shuting down mobile wallet")
 	shutdownRequestChannel <- struct{}{}
 	lw.LockWallet()
+	if lw.wallet != nil {
+		lw.wallet.ResetLockedOutpoints()
+	}
 	err := lw.loader.UnloadWallet()
 	if err != nil {
 		log.Errorf("Failed to close wallet: %v", err)
@@ -180,6 +285,10 @@ func decodeAddress(a string, params *chaincfg.Params) (dcrutil.Address, error) {
 	return addr, nil
 }
 
+// addressGapLimit is the number of unused addresses past the last used
+// address of a branch that the wallet watches.
+const addressGapLimit = 20
+
 func (lw *LibWallet) InitLoader() {
 	stakeOptions := &loader.StakeOptions{
 		VotingEnabled: false,
@@ -188,26 +297,93 @@ func (lw *LibWallet) InitLoader() {
 		TicketFee:     10e8,
 	}
 	l := loader.NewLoader(netparams.TestNet3Params.Params, lw.dataDir, lw.dbDriver, stakeOptions,
-		20, false, 10e5, wallet.DefaultAccountGapLimit)
+		addressGapLimit, false, 10e5, wallet.DefaultAccountGapLimit)
 	lw.loader = l
 	lw.activeNet = &netparams.TestNet3Params
 	lw.chainParams = &chaincfg.TestNet3Params
 	go shutdownListener()
 }
 
+// NetworkParams returns the parameters of the active network that are
+// needed to derive addresses compatible with the wallet.  Address and key
+// prefixes are hex encoded.
+func (lw *LibWallet) NetworkParams() (string, error) {
+	params := lw.chainParams
+	netParams := NetworkParams{
+		Name:                    params.Name,
+		HDPurpose:               44, // BIP0044
+		CoinType:                params.SLIP0044CoinType,
+		LegacyCoinType:          params.LegacyCoinType,
+		PubKeyHashAddrID:        hex.EncodeToString(params.PubKeyHashAddrID[:]),
+		PubKeyHashEdwardsAddrID: hex.EncodeToString(params.PubKeyHashEdwardsAddrID[:]),
+		PubKeyHashSchnorrAddrID: hex.EncodeToString(params.PubKeyHashSchnorrAddrID[:]),
+		ScriptHashAddrID:        hex.EncodeToString(params.ScriptHashAddrID[:]),
+		PrivateKeyID:            hex.EncodeToString(params.PrivateKeyID[:]),
+		HDPrivateKeyID:          hex.EncodeToString(params.HDPrivateKeyID[:]),
+		HDPublicKeyID:           hex.EncodeToString(params.HDPublicKeyID[:]),
+		DefaultPort:             params.DefaultPort,
+		JSONRPCServerPort:       lw.activeNet.JSONRPCServerPort,
+		GRPCServerPort:          lw.activeNet.GRPCServerPort,
+	}
+	result, _ := json.Marshal(netParams)
+	return string(result), nil
+}
+
 func (lw *LibWallet) CreateWallet(passphrase string, seedMnemonic string) error {
+	return lw.CreateWalletWithPublicPassphrase("", passphrase, seedMnemonic)
+}
+
+// CreateWalletWithPublicPassphrase creates a wallet whose public data is
+// encrypted with pubPassphrase.  The same public passphrase must then be
+// passed to OpenWalletWithPublicPassphrase.  An empty pubPassphrase uses the
+// wallet's insecure default public passphrase.
+func (lw *LibWallet) CreateWalletWithPublicPassphrase(pubPassphrase string, passphrase string, seedMnemonic string) error {
+	seed, err := decodeSeedMnemonic(seedMnemonic)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	return lw.createWallet(pubPassphrase, passphrase, seed)
+}
+
+// CreateWalletFromSeedBytes creates a wallet from a raw seed, such as one
+// returned by GenerateSeedBytes, rather than from its mnemonic.
+func (lw *LibWallet) CreateWalletFromSeedBytes(passphrase string, seed []byte) error {
+	if len(seed) < hdkeychain.MinSeedBytes || len(seed) > hdkeychain.MaxSeedBytes {
+		return errors.E(errors.Invalid, fmt.Sprintf("seed must be between %d and %d bytes",
+			hdkeychain.MinSeedBytes, hdkeychain.MaxSeedBytes))
+	}
+	return lw.createWallet("", passphrase, seed)
+}
+
+func (lw *LibWallet) createWallet(pubPassphrase string, passphrase string, seed []byte) error {
 	fmt.Println("Creating wallet")
-	pubPass := []byte(wallet.InsecurePubPassphrase)
-	privPass := []byte(passphrase)
-	seed, err := walletseed.DecodeUserInput(seedMnemonic)
+	exists, err := lw.loader.WalletExists()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if exists {
+		log.Error("wallet already exists")
+		return errors.E(errors.Exist, "wallet already exists")
+	}
+
+	err = lw.checkPassphraseStrength(passphrase)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
+	pubPass := publicPassphrase(pubPassphrase)
+	privPass := []byte(passphrase)
+	defer zeroBytes(privPass)
+
 	w, err := lw.loader.CreateNewWallet(pubPass, privPass, seed)
 	if err != nil {
 		log.Error(err)
+		if errors.Is(errors.Exist, err) {
+			return errors.E(errors.Exist, "wallet already exists")
+		}
 		return err
 	}
 	lw.wallet = w
@@ -216,11 +392,139 @@ func (lw *LibWallet) CreateWallet(passphrase string, seedMnemonic string) error
 	return nil
 }
 
+// SetPassphrasePolicy sets the minimum length of the private passphrase of
+// wallets created from then on.  A minLength of zero, the default, accepts
+// any passphrase.
+func (lw *LibWallet) SetPassphrasePolicy(minLength int32) error {
+	if minLength < 0 {
+		return errors.E(errors.Invalid, "minimum passphrase length must not be negative")
+	}
+	lw.mu.Lock()
+	lw.minPassphraseLength = int(minLength)
+	lw.mu.Unlock()
+	return nil
+}
+
+func (lw *LibWallet) checkPassphraseStrength(passphrase string) error {
+	lw.mu.Lock()
+	minLength := lw.minPassphraseLength
+	lw.mu.Unlock()
+	if minLength == 0 {
+		return nil
+	}
+	if strings.TrimSpace(passphrase) == "" {
+		return errors.E(errors.Invalid, "passphrase must not be empty")
+	}
+	if len([]rune(passphrase)) < minLength {
+		return errors.E(errors.Invalid, fmt.Sprintf("passphrase must be at least %d characters long", minLength))
+	}
+	return nil
+}
+
+// RestoreAndDiscover restores a wallet from its seed and immediately starts
+// an SPV sync that discovers the wallet's accounts.  The wallet is unlocked
+// for the discovery and locked again once the first sync completes.
+func (lw *LibWallet) RestoreAndDiscover(passphrase string, seedMnemonic string, syncResponse SpvSyncResponse, peerAddresses string) error {
+	err := lw.CreateWallet(passphrase, seedMnemonic)
+	if err != nil {
+		return err
+	}
+	return lw.SpvSync(syncResponse, peerAddresses, true, []byte(passphrase))
+}
+
 func (lw *LibWallet) CloseWallet() error {
+	if lw.wallet != nil {
+		lw.wallet.ResetLockedOutpoints()
+	}
 	err := lw.loader.UnloadWallet()
 	return err
 }
 
+// walletDbName is the name of the wallet database file created by the loader
+// in the data directory.
+const walletDbName = "wallet.db"
+
+// ExportWalletDB copies the encrypted wallet database to destPath as a backup
+// of data that cannot be recovered from the seed, such as imported keys.  The
+// wallet must be closed so the database is not modified during the copy.
+func (lw *LibWallet) ExportWalletDB(destPath string) error {
+	err := lw.checkWalletDBCopy()
+	if err != nil {
+		return err
+	}
+	err = copyFile(filepath.Join(lw.dataDir, walletDbName), destPath)
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// ImportWalletDB restores a wallet database previously exported with
+// ExportWalletDB.  The wallet must be closed and no wallet may exist yet, so
+// that an existing wallet is never overwritten.
+func (lw *LibWallet) ImportWalletDB(srcPath string) error {
+	err := lw.checkWalletDBCopy()
+	if err != nil {
+		return err
+	}
+	exists, err := lw.loader.WalletExists()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if exists {
+		return errors.E(errors.Exist, "wallet already exists")
+	}
+	err = os.MkdirAll(lw.dataDir, 0700)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	err = copyFile(srcPath, filepath.Join(lw.dataDir, walletDbName))
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+func (lw *LibWallet) checkWalletDBCopy() error {
+	if lw.dbDriver != "bdb" {
+		return errors.E(errors.Invalid, "database backups are only supported by the bdb driver")
+	}
+	if _, loaded := lw.loader.LoadedWallet(); loaded {
+		return errors.E(errors.Invalid, "wallet must be closed to back up or restore its database")
+	}
+	return nil
+}
+
+// copyFile copies src to dst through a temporary file that is only renamed to
+// dst once completely written.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
 func (lw *LibWallet) GenerateSeed() (string, error) {
 	seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
 	if err != nil {
@@ -231,11 +535,82 @@ func (lw *LibWallet) GenerateSeed() (string, error) {
 	return walletseed.EncodeMnemonic(seed), nil
 }
 
+// GenerateSeedBytes returns a new random seed of the recommended length as
+// raw bytes instead of a mnemonic.
+func (lw *LibWallet) GenerateSeedBytes() ([]byte, error) {
+	seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	return seed, nil
+}
+
+// decodeSeedMnemonic decodes a seed entered or pasted by the user.  Surrounding
+// and repeated whitespace, including line breaks, is collapsed and the input
+// is lowercased since neither the PGP word list nor hex seeds are case
+// sensitive.
+func decodeSeedMnemonic(seedMnemonic string) ([]byte, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(seedMnemonic), " "))
+	return walletseed.DecodeUserInput(normalized)
+}
+
 func (lw *LibWallet) VerifySeed(seedMnemonic string) bool {
-	_, err := walletseed.DecodeUserInput(seedMnemonic)
+	_, err := decodeSeedMnemonic(seedMnemonic)
 	return err == nil
 }
 
+// EncryptSeed encrypts a seed mnemonic for backup with a key derived from
+// exportPass and returns it hex encoded.  The wallet does not keep its seed
+// after creation, so the mnemonic must be provided by the caller, e.g. when
+// it is shown to the user on wallet creation.
+func (lw *LibWallet) EncryptSeed(seedMnemonic string, exportPass []byte) (string, error) {
+	defer zeroBytes(exportPass)
+	if len(exportPass) == 0 {
+		return "", errors.E(errors.Invalid, "export passphrase is required")
+	}
+	seed, err := decodeSeedMnemonic(seedMnemonic)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	defer zeroBytes(seed)
+	encrypted, err := encryptSeed(seed, exportPass)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	return hex.EncodeToString(encrypted), nil
+}
+
+// DecryptSeed decrypts a seed encrypted by EncryptSeed and returns its
+// mnemonic.
+func (lw *LibWallet) DecryptSeed(encryptedSeed string, exportPass []byte) (string, error) {
+	defer zeroBytes(exportPass)
+	encrypted, err := hex.DecodeString(encryptedSeed)
+	if err != nil {
+		log.Error(err)
+		return "", errors.E(errors.Encoding, err)
+	}
+	seed, err := decryptSeed(encrypted, exportPass)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	defer zeroBytes(seed)
+	return walletseed.EncodeMnemonic(seed), nil
+}
+
+// IsWatchingOnly returns whether the loaded wallet is a watching-only wallet,
+// which has no private keys and cannot sign transactions.
+func (lw *LibWallet) IsWatchingOnly() bool {
+	w, ok := lw.loader.LoadedWallet()
+	if !ok {
+		return false
+	}
+	return w.Manager.WatchingOnly()
+}
+
 func (lw *LibWallet) IsNetBackendNil() bool {
 	_, err := lw.wallet.NetworkBackend()
 	if err != nil {
@@ -267,39 +642,251 @@ func (lw *LibWallet) StartRPCClient(rpcHost string, rpcUser string, rpcPass stri
 	}
 
 	lw.netBackend = chain.BackendFromRPCClient(c.Client)
-	lw.wallet.SetNetworkBackend(lw.netBackend)
-	lw.loader.SetNetworkBackend(lw.netBackend)
+	lw.setNetworkBackend(lw.netBackend)
 	lw.rpcClient = c
 	return nil
 }
 
-func (lw *LibWallet) StartSPVConnection(peerAddress string) {
+// SetNetworkBackendListener sets the listener notified when a network backend
+// becomes available to the wallet or stops being available, so that actions
+// requiring the network can be enabled accordingly.
+func (lw *LibWallet) SetNetworkBackendListener(listener NetworkBackendListener) {
+	lw.mu.Lock()
+	lw.backendListener = listener
+	lw.mu.Unlock()
+}
+
+// setNetworkBackend associates n with the wallet, or dissociates the current
+// backend when n is nil, and notifies the backend listener of the change.
+func (lw *LibWallet) setNetworkBackend(n wallet.NetworkBackend) {
+	lw.wallet.SetNetworkBackend(n)
+	if n != nil {
+		lw.loader.SetNetworkBackend(n)
+	}
+
+	lw.mu.Lock()
+	available := n != nil
+	changed := lw.backendAvailable != available
+	lw.backendAvailable = available
+	listener := lw.backendListener
+	lw.mu.Unlock()
+	if !changed || listener == nil {
+		return
+	}
+	if available {
+		listener.OnNetworkBackendConnected()
+	} else {
+		listener.OnNetworkBackendDisconnected()
+	}
+}
+
+// newLocalPeer creates the local peer used by the SPV syncer, dialing remote
+// peers with the configured connection options.  It only depends on the
+// loader's network parameters, so it may be used before a wallet is loaded.
+func (lw *LibWallet) newLocalPeer(addr *net.TCPAddr) *p2p.LocalPeer {
+	lw.mu.Lock()
+	dnsSeedingDisabled := lw.dnsSeedingDisabled
+	dnsSeeds := lw.dnsSeeds
+	lw.mu.Unlock()
+
+	// Peers are seeded from the DNS seeds of the chain parameters, so a
+	// copy of the parameters carries any seed configuration.
+	params := *lw.chainParams
+	lookup := net.LookupIP
+	if dnsSeedingDisabled {
+		params.DNSSeeds = nil
+		lookup = func(host string) ([]net.IP, error) {
+			return nil, errors.E(errors.Invalid, fmt.Sprintf("DNS lookup of %s disabled", host))
+		}
+	} else if len(dnsSeeds) > 0 {
+		params.DNSSeeds = make([]chaincfg.DNSSeed, len(dnsSeeds))
+		for i, host := range dnsSeeds {
+			params.DNSSeeds[i] = chaincfg.DNSSeed{Host: host, HasFiltering: true}
+		}
+	}
+
+	amgrDir := filepath.Join(lw.dataDir, lw.chainParams.Name)
+	amgr := addrmgr.New(amgrDir, lookup) // TODO: be mindful of tor
+	lp := p2p.NewLocalPeer(&params, addr, amgr)
+	lp.SetDialFunc(lw.dialer.dial)
+	return lp
+}
+
+// SetDNSSeeding configures how SPV peers are discovered by syncs started from
+// then on.  When disabled, no DNS lookups are made and only the persistent
+// peers passed to the sync and previously known peer addresses are used.
+// Otherwise seeds, a comma separated list of DNS seed hostnames, replaces the
+// network's default seeds unless it is empty.
+func (lw *LibWallet) SetDNSSeeding(enabled bool, seeds string) {
+	var hosts []string
+	for _, host := range strings.Split(seeds, ",") {
+		host = strings.TrimSpace(host)
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	lw.mu.Lock()
+	lw.dnsSeedingDisabled = !enabled
+	lw.dnsSeeds = hosts
+	lw.mu.Unlock()
+}
+
+// parsePeerAddresses splits a semicolon separated list of peer addresses and
+// normalizes each one with the active network's default port.
+func (lw *LibWallet) parsePeerAddresses(peerAddresses string) ([]string, error) {
+	if len(peerAddresses) == 0 {
+		return nil, nil
+	}
+	addrs := strings.Split(peerAddresses, ";")
+	for i, addr := range addrs {
+		normalized, err := NormalizeAddress(strings.TrimSpace(addr), lw.activeNet.Params.DefaultPort)
+		if err != nil {
+			return nil, errors.E(errors.Invalid, fmt.Sprintf("SPV Connect address invalid: %v", err))
+		}
+		addrs[i] = normalized
+	}
+	return addrs, nil
+}
+
+// maxProbePeers is the number of peers discovered from DNS seeds that
+// ProbeNetwork connects to.
+const maxProbePeers = 8
+
+// ProbeNetwork connects to the peers in the semicolon separated
+// peerAddresses, reads the best height each one advertises in its version
+// message and disconnects, without starting a sync.  When no peers are
+// given, up to maxProbePeers peers are discovered from the DNS seeds
+// configured by SetDNSSeeding.  The highest height advertised is returned; an
+// error is returned when no peer could be reached.  Only InitLoader must have
+// been called; no wallet needs to be loaded.
+func (lw *LibWallet) ProbeNetwork(peerAddresses string) (int32, error) {
+	if lw.chainParams == nil {
+		return 0, errors.E(errors.Invalid, "wallet loader has not been initialized")
+	}
+	peers, err := lw.parsePeerAddresses(peerAddresses)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	if len(peers) == 0 {
+		peers, err = lw.seedPeerAddresses()
+		if err != nil {
+			log.Error(err)
+			return 0, err
+		}
+		if len(peers) > maxProbePeers {
+			peers = peers[:maxProbePeers]
+		}
+	}
+
+	addr := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 0}
+	lp := lw.newLocalPeer(addr)
+	ctx := contextWithShutdownCancel(context.Background())
+	timeout := lw.dialer.connectTimeout()
+
+	bestHeight := int32(-1)
+	var lastErr error
+	for _, peer := range peers {
+		// Peers are probed one after another, so each gets its own
+		// connect timeout.
+		peerCtx, cancel := context.WithTimeout(ctx, timeout)
+		rp, err := lp.ConnectOutbound(peerCtx, peer, wire.SFNodeNetwork)
+		cancel()
+		if err != nil {
+			log.Errorf("Unable to probe peer %v: %v", peer, err)
+			lastErr = err
+			continue
+		}
+		if height := rp.InitialHeight(); height > bestHeight {
+			bestHeight = height
+		}
+		rp.Disconnect(errors.E("network probe finished"))
+	}
+	if bestHeight < 0 {
+		return 0, errors.E(errors.NoPeers, fmt.Sprintf("no peers reachable: %v", lastErr))
+	}
+	return bestHeight, nil
+}
+
+// seedPeerAddresses resolves the DNS seeds configured by SetDNSSeeding, or the
+// network's default seeds, to peer addresses.
+func (lw *LibWallet) seedPeerAddresses() ([]string, error) {
+	lw.mu.Lock()
+	dnsSeedingDisabled := lw.dnsSeedingDisabled
+	hosts := lw.dnsSeeds
+	lw.mu.Unlock()
+	if dnsSeedingDisabled {
+		return nil, errors.E(errors.Invalid, "no peer addresses given and DNS seeding is disabled")
+	}
+	if len(hosts) == 0 {
+		for _, seed := range lw.chainParams.DNSSeeds {
+			hosts = append(hosts, seed.Host)
+		}
+	}
+
+	var addrs []string
+	for _, host := range hosts {
+		ips, err := net.LookupHost(host)
+		if err != nil {
+			log.Errorf("Unable to look up DNS seed %v: %v", host, err)
+			continue
+		}
+		for _, ip := range ips {
+			addrs = append(addrs, net.JoinHostPort(ip, lw.activeNet.Params.DefaultPort))
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, errors.E(errors.NoPeers, "no peers found from DNS seeds")
+	}
+	return addrs, nil
+}
+
+func (lw *LibWallet) StartSPVConnection(peerAddress string) error {
+	//Seperate peer address with a semi-colon ";"
+	peers, err := lw.parsePeerAddresses(peerAddress)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
 	go func() {
 		ctx := contextWithShutdownCancel(context.Background())
 		addr := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 19108}
-		amgrDir := filepath.Join(lw.dataDir, lw.wallet.ChainParams().Name)
-		amgr := addrmgr.New(amgrDir, net.LookupIP) // TODO: be mindful of tor
-		lp := p2p.NewLocalPeer(lw.wallet.ChainParams(), addr, amgr)
+		lp := lw.newLocalPeer(addr)
 		syncer := spv.NewSyncer(lw.wallet, lp)
-		if len(peerAddress) > 0 {
-			//Seperate peer address with a semi-colon ";"
-			syncer.SetPersistantPeers(strings.Split(peerAddress, ";"))
+		if len(peers) > 0 {
+			syncer.SetPersistantPeers(peers)
 		}
-		lw.wallet.SetNetworkBackend(syncer)
-		lw.loader.SetNetworkBackend(syncer)
+		lw.setNetworkBackend(syncer)
+		lw.mu.Lock()
 		lw.spvSyncer = syncer
+		lw.mu.Unlock()
+		defer func() {
+			lw.mu.Lock()
+			if lw.spvSyncer == syncer {
+				lw.spvSyncer = nil
+			}
+			lw.mu.Unlock()
+		}()
 		for {
 			err := syncer.Run(ctx)
 			if done(ctx) {
 				log.Info("Syncer Context is done")
+				lw.setNetworkBackend(nil)
 				return
 			}
 			log.Errorf("SPV synchronization ended: %v", err)
 		}
 	}()
+	return nil
 }
 
+// SpvSync syncs the wallet over SPV in the background, reporting progress to
+// syncResponse.  An error is returned when a sync is already running,
+// including one that was cancelled but has not finished yet.
 func (lw *LibWallet) SpvSync(syncResponse SpvSyncResponse, peerAddresses string, discoverAccounts bool, privatePassphrase []byte) error {
+	// The passphrase is only needed to unlock the wallet, after which it is
+	// cleared regardless of how the call returns.
+	defer zeroBytes(privatePassphrase)
 	wallet, ok := lw.loader.LoadedWallet()
 	if !ok {
 		return errors.E(errors.Invalid, "Wallet has not been loaded")
@@ -308,14 +895,35 @@ func (lw *LibWallet) SpvSync(syncResponse SpvSyncResponse, peerAddresses string,
 	if discoverAccounts && len(privatePassphrase) == 0 {
 		return errors.E(errors.Invalid, "private passphrase is required for discovering accounts")
 	}
+
+	// Only one sync runs at a time.  The sync's ID identifies the state it
+	// owns, so that it is only cleared by the sync that set it.
+	lw.mu.Lock()
+	if lw.syncRunning {
+		lw.mu.Unlock()
+		return errors.E(errors.Invalid, "sync already in progress")
+	}
+	lw.syncRunning = true
+	lw.syncID++
+	syncID := lw.syncID
+	lw.mu.Unlock()
+	started := false
+	defer func() {
+		if !started {
+			lw.mu.Lock()
+			lw.syncRunning = false
+			lw.mu.Unlock()
+		}
+	}()
+
 	var lockWallet func()
 	if discoverAccounts {
 		lock := make(chan time.Time, 1)
+		var lockOnce sync.Once
 		lockWallet = func() {
-			lock <- time.Time{}
-			for i := range privatePassphrase {
-				privatePassphrase[i] = 0
-			}
+			lockOnce.Do(func() {
+				lock <- time.Time{}
+			})
 		}
 		err := wallet.Unlock(privatePassphrase, lock)
 		if err != nil {
@@ -323,62 +931,115 @@ func (lw *LibWallet) SpvSync(syncResponse SpvSyncResponse, peerAddresses string,
 		}
 	}
 	addr := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 0}
-	amgrDir := filepath.Join(lw.dataDir, lw.wallet.ChainParams().Name)
-	amgr := addrmgr.New(amgrDir, net.LookupIP) // TODO: be mindful of tor
-	lp := p2p.NewLocalPeer(wallet.ChainParams(), addr, amgr)
+	lp := lw.newLocalPeer(addr)
+
+	// The wallet does not record its birthday, so header fetch progress is
+	// measured from the time of the wallet's tip when the sync started.
+	headersStartTime, err := lw.GetBestBlockTimeStamp()
+	if err != nil || headersStartTime == 0 {
+		headersStartTime = lw.chainParams.GenesisBlock.Header.Timestamp.Unix()
+	}
 
 	ntfns := &spv.Notifications{
 		Synced: func(sync bool) {
+			lw.mu.Lock()
+			lw.syncState.synced = sync
+			if sync {
+				lw.setSyncPhase(syncPhaseSynced)
+			}
+			lw.mu.Unlock()
 			syncResponse.OnSynced(sync)
 			// Lock the wallet after the first time synced while also
 			// discovering accounts.
 			if sync && lockWallet != nil {
 				lockWallet()
-				lockWallet = nil
 			}
 		},
 		FetchedHeaders: func(peerInitialHeight, fetchedHeadersCount int32, lastHeaderTime int64) {
+			lw.mu.Lock()
+			if peerInitialHeight > lw.syncState.targetHeight {
+				lw.syncState.targetHeight = peerInitialHeight
+			}
+			progress := headersFetchProgress(headersStartTime, lastHeaderTime, time.Now().Unix())
+			lw.setSyncPhase(syncPhaseHeaders)
+			lw.syncState.headersProgress = progress
+			lw.mu.Unlock()
 			syncResponse.OnFetchedHeaders(peerInitialHeight, fetchedHeadersCount, lastHeaderTime)
+			syncResponse.OnFetchedHeadersProgress(progress)
 		},
 		FetchMissingCFilters: func(fetchedCfiltersCount int32) {
+			lw.mu.Lock()
+			lw.setSyncPhase(syncPhaseCFilters)
+			lw.mu.Unlock()
 			syncResponse.OnFetchMissingCFilters(fetchedCfiltersCount)
 		},
 		DiscoveredAddresses: func(finished bool) {
+			lw.mu.Lock()
+			lw.setSyncPhase(syncPhaseDiscovery)
+			lw.mu.Unlock()
 			syncResponse.OnDiscoveredAddresses(finished)
 		},
 		RescanProgress: func(rescannedThrough int32) {
-			syncResponse.OnRescanProgress(rescannedThrough)
+			lw.mu.Lock()
+			if lw.syncState.phase != syncPhaseRescan {
+				lw.syncState.rescanStartHeight = rescannedThrough
+			}
+			lw.setSyncPhase(syncPhaseRescan)
+			lw.syncState.rescanHeight = rescannedThrough
+			lw.mu.Unlock()
+			syncResponse.OnRescanProgress(rescannedThrough, lw.GetBestBlock())
 		},
 		PeerDisconnected: func(peerCount int32) {
+			lw.mu.Lock()
+			lw.syncState.peerCount = peerCount
+			lw.mu.Unlock()
 			syncResponse.OnPeerDisconnected(peerCount)
 		},
 		PeerConnected: func(peerCount int32) {
+			lw.mu.Lock()
+			lw.syncState.peerCount = peerCount
+			lw.mu.Unlock()
 			syncResponse.OnPeerConnected(peerCount)
 		},
 	}
-	var spvConnect []string
-	if len(peerAddresses) > 0 {
-		spvConnect = strings.Split(peerAddresses, ";")
-	}
+	ctx, cancel := context.WithCancel(contextWithShutdownCancel(context.Background()))
+	lw.mu.Lock()
+	lw.cancelSync = cancel
+	lw.syncState = syncState{}
+	lw.setSyncPhase(syncPhaseHeaders)
+	lw.mu.Unlock()
+	started = true
 	go func() {
+		// Make sure the wallet is locked if the sync ends before account
+		// discovery completes.
+		if lockWallet != nil {
+			defer lockWallet()
+		}
+		defer cancel()
+		defer func() {
+			lw.mu.Lock()
+			if lw.syncID == syncID {
+				lw.syncState.synced = false
+				lw.syncState.peerCount = 0
+				lw.setSyncPhase(syncPhaseIdle)
+				lw.cancelSync = nil
+				lw.syncRunning = false
+			}
+			lw.mu.Unlock()
+		}()
 		syncer := spv.NewSyncer(wallet, lp)
 		syncer.SetNotifications(ntfns)
-		if len(spvConnect) > 0 {
-			spvConnects := make([]string, len(spvConnect))
-			for i := 0; i < len(spvConnect); i++ {
-				spvConnect, err := NormalizeAddress(spvConnect[i], lw.activeNet.Params.DefaultPort)
-				if err != nil {
-					syncResponse.OnSyncError(3, errors.E("SPV Connect address invalid: %v", err))
-					return
-				}
-				spvConnects[i] = spvConnect
-			}
+		spvConnects, err := lw.parsePeerAddresses(peerAddresses)
+		if err != nil {
+			syncResponse.OnSyncError(3, err)
+			return
+		}
+		if len(spvConnects) > 0 {
 			syncer.SetPersistantPeers(spvConnects)
 		}
-		wallet.SetNetworkBackend(syncer)
-		lw.loader.SetNetworkBackend(syncer)
-		ctx := contextWithShutdownCancel(context.Background())
-		err := syncer.Run(ctx)
+		lw.setNetworkBackend(syncer)
+		defer lw.setNetworkBackend(nil)
+		err = syncer.Run(ctx)
 		if err != nil {
 			if err == context.Canceled {
 				syncResponse.OnSyncError(1, errors.E("SPV synchronization canceled: %v", err))
@@ -394,15 +1055,249 @@ func (lw *LibWallet) SpvSync(syncResponse SpvSyncResponse, peerAddresses string,
 	return nil
 }
 
-func (lw *LibWallet) RescanPoint() []byte {
+// IsSynced returns whether the last SPV sync notification reported the wallet
+// as synced and the wallet has not fallen behind the best height reported by
+// its peers since.
+func (lw *LibWallet) IsSynced() bool {
+	lw.mu.Lock()
+	synced := lw.syncState.synced
+	targetHeight := lw.syncState.targetHeight
+	lw.mu.Unlock()
+	return synced && lw.GetBestBlock() >= targetHeight
+}
+
+// SyncTargetHeight returns the best block height reported by the peers of
+// the running SPV sync, or -1 when no sync is running or no peer has reported
+// its height yet.
+func (lw *LibWallet) SyncTargetHeight() int32 {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.cancelSync == nil || lw.syncState.targetHeight == 0 {
+		return -1
+	}
+	return lw.syncState.targetHeight
+}
+
+// BlocksBehind returns the number of blocks the wallet is behind the best
+// height reported by its peers, 0 when it has caught up and -1 when the
+// target height is unknown.
+func (lw *LibWallet) BlocksBehind() int32 {
+	targetHeight := lw.SyncTargetHeight()
+	if targetHeight < 0 {
+		return -1
+	}
+	behind := targetHeight - lw.GetBestBlock()
+	if behind < 0 {
+		return 0
+	}
+	return behind
+}
+
+// HeadersRemaining returns the estimated number of headers left to fetch to
+// reach the best height reported by the sync's peers, or -1 when that height
+// is unknown.  Fetched headers are added to the main chain as they arrive, so
+// this is the distance from the main chain tip to the peers' best height.
+func (lw *LibWallet) HeadersRemaining() int32 {
+	return lw.BlocksBehind()
+}
+
+// Approximate sizes of the messages downloaded per block by an SPV sync.  A
+// headers message entry is a serialized header followed by a zero
+// transaction count; committed filter sizes vary with the number of scripts
+// in a block and the average is a rough estimate.
+const (
+	estimatedHeaderSize  = wire.MaxBlockHeaderPayload + 1
+	estimatedCFilterSize = 24 + chainhash.HashSize + 1 + 300
+)
+
+// EstimateSyncDataUsage estimates the number of bytes an SPV sync downloads
+// to catch up from the wallet's best block to the network tip.  The tip
+// height reported by peers is used when a sync is running, otherwise the
+// number of missing blocks is estimated from the time since the best block.
+func (lw *LibWallet) EstimateSyncDataUsage() (int64, error) {
+	targetHeight := lw.SyncTargetHeight()
+	blocks := int64(targetHeight - lw.GetBestBlock())
+	if targetHeight < 0 {
+		bestTime, err := lw.GetBestBlockTimeStamp()
+		if err != nil {
+			return 0, err
+		}
+		elapsed := time.Since(time.Unix(bestTime, 0))
+		blocks = int64(elapsed / lw.chainParams.TargetTimePerBlock)
+	}
+	if blocks <= 0 {
+		return 0, nil
+	}
+	return blocks * (estimatedHeaderSize + estimatedCFilterSize), nil
+}
+
+// SyncStatus returns a snapshot of the SPV sync progress as JSON, so that the
+// height, phase, progress and peer count are read together rather than over
+// several calls.  Percentage is the progress of the current phase.
+func (lw *LibWallet) SyncStatus() (string, error) {
+	bestHeight := lw.GetBestBlock()
+
+	lw.mu.Lock()
+	state := lw.syncState
+	syncing := lw.cancelSync != nil
+	lw.mu.Unlock()
+
+	status := SyncStatus{
+		Syncing:                   syncing,
+		Synced:                    state.synced && bestHeight >= state.targetHeight,
+		Phase:                     state.phase,
+		BestBlockHeight:           bestHeight,
+		TargetHeight:              state.targetHeight,
+		EstimatedSecondsRemaining: -1,
+		PeerCount:                 state.peerCount,
+		Rescanning:                state.phase == syncPhaseRescan,
+		RescanHeight:              state.rescanHeight,
+	}
+	if status.Phase == "" {
+		status.Phase = syncPhaseIdle
+	}
+
+	switch status.Phase {
+	case syncPhaseHeaders:
+		status.Percentage = state.headersProgress
+	case syncPhaseRescan:
+		remaining := int64(bestHeight - state.rescanStartHeight)
+		if remaining > 0 {
+			scanned := int64(state.rescanHeight - state.rescanStartHeight)
+			status.Percentage = int32(scanned * 100 / remaining)
+		}
+	case syncPhaseSynced:
+		status.Percentage = 100
+		status.EstimatedSecondsRemaining = 0
+	}
+	if status.Percentage > 0 && status.Percentage < 100 {
+		elapsed := time.Now().Unix() - state.phaseStartTime
+		status.EstimatedSecondsRemaining = elapsed * int64(100-status.Percentage) / int64(status.Percentage)
+	}
+
+	result, _ := json.Marshal(status)
+	return string(result), nil
+}
+
+// headersFetchProgress estimates the percentage of headers fetched from the
+// time of the last fetched header, relative to the time the fetch started
+// from and the current time.  Unlike a count based estimate, this does not
+// depend on knowing the height of the network tip.
+func headersFetchProgress(startTime, lastHeaderTime, now int64) int32 {
+	if now <= startTime {
+		return 100
+	}
+	if lastHeaderTime <= startTime {
+		return 0
+	}
+	progress := (lastHeaderTime - startTime) * 100 / (now - startTime)
+	if progress > 100 {
+		return 100
+	}
+	return int32(progress)
+}
+
+// ResetPeerAddresses deletes the address manager's saved peers so that peers
+// are discovered afresh on the next sync.  Any running sync must be cancelled
+// first, otherwise the address manager would save its peers again.
+func (lw *LibWallet) ResetPeerAddresses() error {
+	lw.mu.Lock()
+	syncing := lw.cancelSync != nil || lw.spvSyncer != nil
+	lw.mu.Unlock()
+	if syncing {
+		return errors.E(errors.Invalid, "peer addresses cannot be reset while syncing")
+	}
+	peersFile := filepath.Join(lw.dataDir, lw.chainParams.Name, "peers.json")
+	err := os.Remove(peersFile)
+	if err != nil && !os.IsNotExist(err) {
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// CancelSync stops a running SPV sync.  If the sync was discovering accounts,
+// the wallet is locked as the sync ends.
+func (lw *LibWallet) CancelSync() {
+	lw.mu.Lock()
+	cancel := lw.cancelSync
+	lw.cancelSync = nil
+	lw.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// IsHeightScanned returns whether transactions of the wallet in the main
+// chain block at height would have been detected.  Blocks from the wallet's
+// rescan point on, which are recorded as needing a rescan after addresses
+// were discovered or imported, and blocks past the main chain tip are not
+// scanned.
+func (lw *LibWallet) IsHeightScanned(height int32) (bool, error) {
+	if height < 0 {
+		return false, errors.E(errors.Invalid, "height must not be negative")
+	}
+	_, tipHeight := lw.wallet.MainChainTip()
+	if height > tipHeight {
+		return false, nil
+	}
+	rescanPoint, err := lw.wallet.RescanPoint()
+	if err != nil {
+		log.Error(err)
+		return false, err
+	}
+	if rescanPoint == nil {
+		return true, nil
+	}
+	info, err := lw.wallet.BlockInfo(wallet.NewBlockIdentifierFromHash(rescanPoint))
+	if err != nil {
+		log.Error(err)
+		return false, err
+	}
+	return height < info.Height, nil
+}
+
+// ScannedBlockCount returns the number of main chain blocks, starting at the
+// genesis block, whose transactions have been processed by the wallet.  The
+// wallet does not record a birthday, so blocks before it are counted as well.
+// Blocks from the rescan point on are not counted until they are rescanned.
+func (lw *LibWallet) ScannedBlockCount() (int32, error) {
+	_, tipHeight := lw.wallet.MainChainTip()
+	rescanPoint, err := lw.wallet.RescanPoint()
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	if rescanPoint == nil {
+		return tipHeight + 1, nil
+	}
+	info, err := lw.wallet.BlockInfo(wallet.NewBlockIdentifierFromHash(rescanPoint))
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return info.Height, nil
+}
+
+// RescanPoint returns the hash of the first block that needs to be rescanned,
+// or nil when no rescan is needed.
+func (lw *LibWallet) RescanPoint() ([]byte, error) {
 	rescanPoint, err := lw.wallet.RescanPoint()
 	if err != nil {
-		fmt.Println("Couldn't get rescan point:", err)
+		log.Errorf("Couldn't get rescan point: %v", err)
+		return nil, err
 	}
 	if rescanPoint != nil {
-		return rescanPoint[:]
+		return rescanPoint[:], nil
+	}
+	return nil, nil
+}
+
+// zeroBytes clears a passphrase once it is no longer needed.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
 	}
-	return nil
 }
 
 func done(ctx context.Context) bool {
@@ -435,6 +1330,116 @@ func (lw *LibWallet) DiscoverActiveAddresses() error {
 	return err
 }
 
+// DiscoverActiveAddressesSPV discovers the used addresses of the wallet using
+// committed filters from the wallet's network backend, so that it works with
+// an SPV syncer and does not require an RPC server.  When privPass is
+// provided, used accounts are discovered and created as well.  Discovery runs
+// in the background and reports to listener; a Rescan should follow to load
+// the transactions of the discovered addresses.
+func (lw *LibWallet) DiscoverActiveAddressesSPV(privPass []byte, listener AccountDiscoveryListener) error {
+	defer zeroBytes(privPass)
+	n, err := lw.wallet.NetworkBackend()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	discoverAccounts := len(privPass) > 0
+	var lock chan time.Time
+	if discoverAccounts {
+		lock = make(chan time.Time, 1)
+		err = lw.wallet.Unlock(privPass, lock)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
+	go func() {
+		if lock != nil {
+			defer func() {
+				lock <- time.Time{}
+			}()
+		}
+		listener.OnDiscoveryStarted()
+		ctx := contextWithShutdownCancel(context.Background())
+		err := lw.wallet.DiscoverActiveAddresses(ctx, n, lw.chainParams.GenesisHash, discoverAccounts)
+		if err != nil {
+			log.Error(err)
+			listener.OnDiscoveryError(err)
+			return
+		}
+		resp, err := lw.wallet.Accounts()
+		if err != nil {
+			log.Error(err)
+			listener.OnDiscoveryError(err)
+			return
+		}
+		listener.OnDiscoveryFinished(int32(len(resp.Accounts)))
+	}()
+	return nil
+}
+
+// RediscoverAccount extends the addresses watched for a single account to
+// gapLimit addresses past the last used address on both the external and
+// internal branches, then rescans from startHeight, reporting to response as
+// Rescan does, so that funds sent to a sparsely used account are found
+// without raising the gap limit of every account.  Only addresses that have
+// not been derived yet are added, so the account's next returned address
+// moves at most to the end of the new gap.
+func (lw *LibWallet) RediscoverAccount(account int32, gapLimit int32, startHeight int32, response BlockScanResponse) error {
+	if gapLimit <= 0 {
+		return errors.E(errors.Invalid, "gap limit must be positive")
+	}
+	_, err := lw.wallet.NetworkBackend()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	resp, err := lw.wallet.Accounts()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	var props *udb.AccountProperties
+	for i := range resp.Accounts {
+		if resp.Accounts[i].AccountNumber == uint32(account) {
+			props = &resp.Accounts[i].AccountProperties
+			break
+		}
+	}
+	if props == nil || props.AccountNumber == udb.ImportedAddrAccount {
+		return errors.E(errors.NotExist, fmt.Sprintf("no HD account %d", account))
+	}
+	extIndex, intIndex, err := lw.wallet.BIP0044BranchNextIndexes(props.AccountNumber)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	// The last used index is ^uint32(0) when no address of the branch is
+	// used, which wraps the end of the gap to gapLimit.
+	extEnd := props.LastUsedExternalIndex + 1 + uint32(gapLimit)
+	intEnd := props.LastUsedInternalIndex + 1 + uint32(gapLimit)
+	for ; extIndex < extEnd; extIndex++ {
+		_, err = lw.wallet.NewExternalAddress(props.AccountNumber, wallet.WithGapPolicyIgnore())
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+	for ; intIndex < intEnd; intIndex++ {
+		_, err = lw.wallet.NewInternalAddress(props.AccountNumber, wallet.WithGapPolicyIgnore())
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
+	return lw.Rescan(startHeight, response)
+}
+
 func (lw *LibWallet) FetchHeaders() (int32, error) {
 	fmt.Println("Fetching Headers")
 	count, _, rescanFromHeight, _, _, err := lw.wallet.FetchHeaders(contextWithShutdownCancel(context.Background()), lw.netBackend)
@@ -458,6 +1463,102 @@ func (lw *LibWallet) LoadActiveDataFilters() error {
 	return err
 }
 
+// CFilterMatchesAtHeight reports which of the wallet's addresses match the
+// committed filter of the main chain block at height, to diagnose whether a
+// missed transaction was not matched by the filter or matched but not
+// processed.  Addresses are derived for every HD account through the address
+// gap limit past the last used address, or through the last returned address
+// when it is further; imported addresses are not checked.
+func (lw *LibWallet) CFilterMatchesAtHeight(height int32) (string, error) {
+	n, err := lw.wallet.NetworkBackend()
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	info, err := lw.wallet.BlockInfo(wallet.NewBlockIdentifierFromHeight(height))
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	blockHash := info.Hash
+	ctx := contextWithShutdownCancel(context.Background())
+	filters, err := n.CFilters(ctx, []*chainhash.Hash{&blockHash})
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	filter := filters[0]
+	key := blockcf.Key(&blockHash)
+
+	resp := CFilterMatch{
+		Height:           height,
+		BlockHash:        blockHash.String(),
+		MatchedAddresses: make([]string, 0),
+	}
+	accounts, err := lw.wallet.Accounts()
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	for _, a := range accounts.Accounts {
+		if a.AccountNumber == udb.ImportedAddrAccount {
+			continue
+		}
+		xpub, err := lw.wallet.MasterPubKey(a.AccountNumber)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		extIndex, intIndex, err := lw.wallet.BIP0044BranchNextIndexes(a.AccountNumber)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		// The last used index is ^uint32(0) when no address of the
+		// branch is used, which wraps the end of the gap to the gap limit.
+		lastUsed := []uint32{a.LastUsedExternalIndex, a.LastUsedInternalIndex}
+		for branch, nextIndex := range []uint32{extIndex, intIndex} {
+			branchKey, err := xpub.Child(uint32(branch))
+			if err != nil {
+				log.Error(err)
+				return "", err
+			}
+			end := lastUsed[branch] + 1 + addressGapLimit
+			if nextIndex > end {
+				end = nextIndex
+			}
+			for i := uint32(0); i < end; i++ {
+				child, err := branchKey.Child(i)
+				if err == hdkeychain.ErrInvalidChild {
+					continue
+				}
+				if err != nil {
+					log.Error(err)
+					return "", err
+				}
+				addr, err := child.Address(lw.chainParams)
+				if err != nil {
+					log.Error(err)
+					return "", err
+				}
+				pkScript, err := txscript.PayToAddrScript(addr)
+				if err != nil {
+					log.Error(err)
+					return "", err
+				}
+				resp.AddressesChecked++
+				if filter.Match(key, pkScript) {
+					resp.Matched = true
+					resp.MatchedAddresses = append(resp.MatchedAddresses, addr.EncodeAddress())
+				}
+			}
+		}
+	}
+
+	result, _ := json.Marshal(resp)
+	return string(result), nil
+}
+
 func int32ToString(arr []int32) []string {
 	var result []string
 	for _, i := range arr {
@@ -467,67 +1568,43 @@ func int32ToString(arr []int32) []string {
 }
 
 func (lw *LibWallet) TransactionNotification(listener TransactionListener) {
+	lw.transactionNotifications(listener, func(*wallet.TransactionSummary) bool { return true })
+}
+
+// AccountTransactionNotification notifies listener like
+// TransactionNotification, but only of transactions crediting or debiting
+// account.  Attached blocks are notified regardless of the account.
+func (lw *LibWallet) AccountTransactionNotification(account int32, listener TransactionListener) {
+	lw.transactionNotifications(listener, func(transaction *wallet.TransactionSummary) bool {
+		return txAffectsAccount(transaction, uint32(account))
+	})
+}
+
+func txAffectsAccount(transaction *wallet.TransactionSummary, account uint32) bool {
+	for _, credit := range transaction.MyOutputs {
+		if credit.Account == account {
+			return true
+		}
+	}
+	for _, debit := range transaction.MyInputs {
+		if debit.PreviousAccount == account {
+			return true
+		}
+	}
+	return false
+}
+
+func (lw *LibWallet) transactionNotifications(listener TransactionListener, include func(*wallet.TransactionSummary) bool) {
 	go func() {
 		n := lw.wallet.NtfnServer.TransactionNotifications()
 		defer n.Done()
 		for {
 			v := <-n.C
 			for _, transaction := range v.UnminedTransactions {
-				var amount int64
-				var inputAmounts int64
-				var outputAmounts int64
-				tempCredits := make([]TransactionCredit, len(transaction.MyOutputs))
-				for index, credit := range transaction.MyOutputs {
-					outputAmounts += int64(credit.Amount)
-					tempCredits[index] = TransactionCredit{
-						Index:    int32(credit.Index),
-						Account:  int32(credit.Account),
-						Internal: credit.Internal,
-						Amount:   int64(credit.Amount),
-						Address:  credit.Address.String()}
-				}
-				tempDebits := make([]TransactionDebit, len(transaction.MyInputs))
-				for index, debit := range transaction.MyInputs {
-					inputAmounts += int64(debit.PreviousAmount)
-					tempDebits[index] = TransactionDebit{
-						Index:           int32(debit.Index),
-						PreviousAccount: int32(debit.PreviousAccount),
-						PreviousAmount:  int64(debit.PreviousAmount),
-						AccountName:     lw.GetAccountName(int32(debit.PreviousAccount))}
-				}
-				var direction int32
-				amountDifference := outputAmounts - inputAmounts
-				if amountDifference < 0 && (float64(transaction.Fee) == math.Abs(float64(amountDifference))) {
-					//Transfered
-					direction = 2
-					amount = int64(transaction.Fee)
-				} else if amountDifference > 0 {
-					//Received
-					direction = 1
-					for _, credit := range transaction.MyOutputs {
-						amount += int64(credit.Amount)
-					}
-				} else {
-					//Sent
-					direction = 0
-					for _, debit := range transaction.MyInputs {
-						amount += int64(debit.PreviousAmount)
-					}
-					for _, credit := range transaction.MyOutputs {
-						amount -= int64(credit.Amount)
-					}
-					amount -= int64(transaction.Fee)
+				if !include(&transaction) {
+					continue
 				}
-				tempTransaction := Transaction{
-					Fee:       int64(transaction.Fee),
-					Hash:      fmt.Sprintf("%02x", reverse(transaction.Hash[:])),
-					Timestamp: transaction.Timestamp,
-					Type:      transactionType(transaction.Type),
-					Credits:   &tempCredits,
-					Amount:    amount,
-					Height:    -1,
-					Direction: direction,
-					Debits:    &tempDebits}
+				tempTransaction := lw.parseTxSummary(&transaction, nil)
 				fmt.Println("New Transaction")
 				result, err := json.Marshal(tempTransaction)
 				if err != nil {
@@ -539,6 +1616,9 @@ func (lw *LibWallet) TransactionNotification(listener TransactionListener) {
 			for _, block := range v.AttachedBlocks {
 				listener.OnBlockAttached(int32(block.Header.Height))
 				for _, transaction := range block.Transactions {
+					if !include(&transaction) {
+						continue
+					}
 					listener.OnTransactionConfirmed(fmt.Sprintf("%02x", reverse(transaction.Hash[:])), int32(block.Header.Height))
 				}
 			}
@@ -546,8 +1626,66 @@ func (lw *LibWallet) TransactionNotification(listener TransactionListener) {
 	}()
 }
 
+// WaitForConfirmations waits in the background for a wallet transaction to
+// reach the given number of confirmations.  The listener is notified once the
+// target is reached, or if the transaction is removed from the main chain
+// after having been mined.
+func (lw *LibWallet) WaitForConfirmations(txHash []byte, confirmations int32, listener ConfirmationListener) error {
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if confirmations < 1 {
+		return errors.E(errors.Invalid, "confirmations must be positive")
+	}
+
+	// Register for notifications before reading the current confirmations
+	// so that no block is missed in between.
+	n := lw.wallet.NtfnServer.TransactionNotifications()
+	_, confs, _, err := lw.wallet.TransactionSummary(hash)
+	if err != nil {
+		n.Done()
+		log.Error(err)
+		return err
+	}
+
+	go func() {
+		defer n.Done()
+		mined := confs > 0
+		for confs < confirmations {
+			var v *wallet.TransactionNotifications
+			select {
+			case v = <-n.C:
+			case <-shutdownSignaled:
+				return
+			}
+			if len(v.AttachedBlocks) == 0 && len(v.DetachedBlocks) == 0 {
+				continue
+			}
+			_, confs, _, err = lw.wallet.TransactionSummary(hash)
+			if err != nil {
+				if errors.Is(errors.NotExist, err) {
+					listener.OnReorganized(hash.String())
+					return
+				}
+				log.Error(err)
+				listener.OnError(err)
+				return
+			}
+			if mined && confs == 0 {
+				listener.OnReorganized(hash.String())
+				return
+			}
+			mined = confs > 0
+		}
+		listener.OnConfirmed(hash.String(), confs)
+	}()
+	return nil
+}
+
 func (lw *LibWallet) SubscribeToBlockNotifications(listener BlockNotificationError) error {
-	wallet, ok := lw.loader.LoadedWallet()
+	_, ok := lw.loader.LoadedWallet()
 	if !ok {
 		log.Error("Wallet has not been loaded")
 		return errors.New("Wallet has not been loaded")
@@ -562,7 +1700,7 @@ func (lw *LibWallet) SubscribeToBlockNotifications(listener BlockNotificationErr
 		log.Error(err)
 		return err
 	}
-	wallet.SetNetworkBackend(chain.BackendFromRPCClient(lw.rpcClient.Client))
+	lw.setNetworkBackend(chain.BackendFromRPCClient(lw.rpcClient.Client))
 	go func() {
 		syncer := chain.NewRPCSyncer(lw.wallet, lw.rpcClient)
 		err = syncer.Run(contextWithShutdownCancel(context.Background()), false)
@@ -572,68 +1710,135 @@ func (lw *LibWallet) SubscribeToBlockNotifications(listener BlockNotificationErr
 			return
 		}
 		lw.netBackend = nil
-		wallet.SetNetworkBackend(nil)
+		lw.setNetworkBackend(nil)
 		listener.OnBlockNotificationError(err)
 		log.Error(err)
 	}()
 	return nil
 }
 
+// publicPassphrase returns the public passphrase to use for the wallet,
+// defaulting to the insecure public passphrase when none was chosen.
+func publicPassphrase(pubPassphrase string) []byte {
+	if pubPassphrase == "" {
+		return []byte(wallet.InsecurePubPassphrase)
+	}
+	return []byte(pubPassphrase)
+}
+
 func (lw *LibWallet) OpenWallet() error {
+	return lw.OpenWalletWithPublicPassphrase("")
+}
 
-	pubPass := []byte(wallet.InsecurePubPassphrase)
+// OpenWalletWithPublicPassphrase opens a wallet created with
+// CreateWalletWithPublicPassphrase.
+func (lw *LibWallet) OpenWalletWithPublicPassphrase(pubPassphrase string) error {
+	pubPass := publicPassphrase(pubPassphrase)
 	w, err := lw.loader.OpenExistingWallet(pubPass)
 	if err != nil {
 		log.Error(err)
+		if errors.Is(errors.Passphrase, err) {
+			return errors.E(errors.Passphrase, "wrong public passphrase")
+		}
 		return err
 	}
 	lw.wallet = w
 	return nil
 }
 
-func (lw *LibWallet) Rescan(startHeight int32, response BlockScanResponse) {
-	go func() {
-		if lw.netBackend == nil {
-			response.OnError(1, "No network backend")
-			return
-		}
-		if startHeight < 0 {
-			response.OnError(2, "Begin height must be non-negative")
+// Rescan rescans the blockchain from startHeight in the background, reporting
+// progress to response.  Only one rescan runs at a time; an error is
+// returned when a rescan is already in progress.
+func (lw *LibWallet) Rescan(startHeight int32, response BlockScanResponse) error {
+	err := lw.reserveRescan()
+	if err != nil {
+		return err
+	}
+	go lw.runRescan(startHeight, response)
+	return nil
+}
+
+// reserveRescan marks a rescan as running, or returns an error when one
+// already is.  Every rescan of the wallet must be reserved so that rescans
+// never overlap.
+func (lw *LibWallet) reserveRescan() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.rescanning {
+		return errors.E(errors.Invalid, "rescan already in progress")
+	}
+	lw.rescanning = true
+	return nil
+}
+
+func (lw *LibWallet) releaseRescan() {
+	lw.mu.Lock()
+	lw.rescanning = false
+	lw.mu.Unlock()
+}
+
+// runRescan rescans from startHeight, reporting to response, and releases the
+// reservation made by reserveRescan once it finishes.
+func (lw *LibWallet) runRescan(startHeight int32, response BlockScanResponse) {
+	defer lw.releaseRescan()
+	n, err := lw.wallet.NetworkBackend()
+	if err != nil {
+		log.Error(err)
+		response.OnError(1, "No network backend")
+		return
+	}
+	if startHeight < 0 {
+		response.OnError(2, "Begin height must be non-negative")
+		return
+	}
+	progress := make(chan wallet.RescanProgress, 1)
+	// The rescan is cancelled when the response stops it, so that it
+	// does not overlap with a following rescan.
+	ctx, cancel := context.WithCancel(contextWithShutdownCancel(context.Background()))
+	defer cancel()
+	var totalHeight int32
+	go lw.wallet.RescanProgressFromHeight(ctx, n, startHeight, progress)
+	for p := range progress {
+		if p.Err != nil {
+			log.Error(p.Err)
+			response.OnError(-1, p.Err.Error())
 			return
 		}
-		progress := make(chan wallet.RescanProgress, 1)
-		ctx := contextWithShutdownCancel(context.Background())
-		n, _ := lw.wallet.NetworkBackend()
-		var totalHeight int32
-		go lw.wallet.RescanProgressFromHeight(ctx, n, startHeight, progress)
-		for p := range progress {
-			if p.Err != nil {
-				log.Error(p.Err)
-				response.OnError(-1, p.Err.Error())
-				return
-			}
-			totalHeight += p.ScannedThrough
-			if !response.OnScan(p.ScannedThrough) {
-				break
+		totalHeight += p.ScannedThrough
+		if !response.OnScan(p.ScannedThrough, lw.GetBestBlock()) {
+			cancel()
+			// Let the rescan observe the cancellation and
+			// close the progress channel.
+			for range progress {
 			}
+			break
 		}
-		select {
-		case <-ctx.Done():
-			response.OnEnd(totalHeight, true)
-		default:
-			response.OnEnd(totalHeight, false)
-		}
-	}()
+	}
+	select {
+	case <-ctx.Done():
+		response.OnEnd(totalHeight, true)
+	default:
+		response.OnEnd(totalHeight, false)
+	}
 }
 
-func (lw *LibWallet) IsAddressMine(address string) bool {
+// IsAddressMine returns whether an address belongs to the wallet.  An error
+// is returned for invalid addresses and when the wallet cannot be queried.
+func (lw *LibWallet) IsAddressMine(address string) (bool, error) {
 	addr, err := decodeAddress(address, lw.wallet.ChainParams())
 	if err != nil {
 		log.Error(err)
-		return false
+		return false, err
 	}
 	_, err = lw.wallet.AddressInfo(addr)
-	return err == nil
+	if errors.Is(errors.NotExist, err) {
+		return false, nil
+	}
+	if err != nil {
+		log.Error(err)
+		return false, err
+	}
+	return true, nil
 }
 
 func (lw *LibWallet) IsAddressValid(address string) bool {
@@ -645,90 +1850,182 @@ func (lw *LibWallet) IsAddressValid(address string) bool {
 	return true
 }
 
-func (lw *LibWallet) GetAccountName(account int32) string {
+func (lw *LibWallet) GetAccountName(account int32) (string, error) {
 	name, err := lw.wallet.AccountName(uint32(account))
 	if err != nil {
 		log.Error(err)
+		return "", err
+	}
+	return name, nil
+}
+
+// accountName returns the name of an account for display alongside other
+// data, falling back to a placeholder when the name cannot be read.
+func (lw *LibWallet) accountName(account int32) string {
+	name, err := lw.GetAccountName(account)
+	if err != nil {
 		return "Account not found"
 	}
 	return name
 }
 
-func (lw *LibWallet) GetAccountByAddress(address string) string {
+// GetAccountByAddress returns the name of the account an address of the
+// wallet belongs to.
+func (lw *LibWallet) GetAccountByAddress(address string) (string, error) {
 	addr, err := dcrutil.DecodeAddress(address)
 	if err != nil {
 		log.Error(err)
-		return "Address decode error"
+		return "", err
+	}
+	info, err := lw.wallet.AddressInfo(addr)
+	if err != nil {
+		log.Error(err)
+		return "", err
 	}
-	info, _ := lw.wallet.AddressInfo(addr)
 	return lw.GetAccountName(int32(info.Account()))
 }
 
+// Filters accepted by GetFilteredTransactions.
+const (
+	TxFilterAll int32 = iota
+	TxFilterRegular
+	TxFilterStaking
+	TxFilterCoinbase
+)
+
+func txMatchesFilter(txType wallet.TransactionType, filter int32) bool {
+	switch filter {
+	case TxFilterRegular:
+		return txType == wallet.TransactionTypeRegular
+	case TxFilterStaking:
+		return txType == wallet.TransactionTypeTicketPurchase ||
+			txType == wallet.TransactionTypeVote ||
+			txType == wallet.TransactionTypeRevocation
+	case TxFilterCoinbase:
+		return txType == wallet.TransactionTypeCoinbase
+	default:
+		return true
+	}
+}
+
+// parseTxSummary converts a wallet transaction summary into the Transaction
+// reported to callers.  header is the header of the block the transaction is
+// mined in, or nil for unmined transactions, which have a height of -1.
+func (lw *LibWallet) parseTxSummary(transaction *wallet.TransactionSummary, header *wire.BlockHeader) Transaction {
+	var height int32 = -1
+	var blockTimestamp int64
+	if header != nil {
+		height = int32(header.Height)
+		blockTimestamp = header.Timestamp.Unix()
+	}
+	tempCredits := make([]TransactionCredit, len(transaction.MyOutputs))
+	for index, credit := range transaction.MyOutputs {
+		tempCredits[index] = TransactionCredit{
+			Index:    int32(credit.Index),
+			Account:  int32(credit.Account),
+			Internal: credit.Internal,
+			Amount:   int64(credit.Amount),
+			Address:  credit.Address.String()}
+	}
+	tempDebits := make([]TransactionDebit, len(transaction.MyInputs))
+	for index, debit := range transaction.MyInputs {
+		tempDebits[index] = TransactionDebit{
+			Index:           int32(debit.Index),
+			PreviousAccount: int32(debit.PreviousAccount),
+			PreviousAmount:  int64(debit.PreviousAmount),
+			AccountName:     lw.accountName(int32(debit.PreviousAccount))}
+	}
+	direction, amount := txDirection(transaction)
+	return Transaction{
+		Fee:              int64(transaction.Fee),
+		Hash:             transaction.Hash.String(),
+		Timestamp:        transaction.Timestamp,
+		BlockTimestamp:   blockTimestamp,
+		Type:             transactionType(transaction.Type),
+		Credits:          &tempCredits,
+		Amount:           amount,
+		Height:           height,
+		Direction:        direction,
+		AffectedAccounts: lw.affectedAccounts(transaction),
+		Debits:           &tempDebits}
+}
+
+// affectedAccounts returns the accounts credited or debited by a transaction,
+// ordered by account number.
+func (lw *LibWallet) affectedAccounts(transaction *wallet.TransactionSummary) []AccountName {
+	seen := make(map[uint32]struct{})
+	for _, credit := range transaction.MyOutputs {
+		seen[credit.Account] = struct{}{}
+	}
+	for _, debit := range transaction.MyInputs {
+		seen[debit.PreviousAccount] = struct{}{}
+	}
+	accounts := make([]AccountName, 0, len(seen))
+	for account := range seen {
+		accounts = append(accounts, AccountName{
+			Number: int32(account),
+			Name:   lw.accountName(int32(account)),
+		})
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return uint32(accounts[i].Number) < uint32(accounts[j].Number)
+	})
+	return accounts
+}
+
+// txDirection returns whether a transaction was sent (0), received (1) or
+// transferred between the wallet's accounts (2), along with the amount sent,
+// received or, for transfers, paid in fees.
+func txDirection(transaction *wallet.TransactionSummary) (direction int32, amount int64) {
+	var inputAmounts int64
+	var outputAmounts int64
+	for _, credit := range transaction.MyOutputs {
+		outputAmounts += int64(credit.Amount)
+	}
+	for _, debit := range transaction.MyInputs {
+		inputAmounts += int64(debit.PreviousAmount)
+	}
+	amountDifference := outputAmounts - inputAmounts
+	if amountDifference < 0 && (float64(transaction.Fee) == math.Abs(float64(amountDifference))) {
+		//Transfered
+		direction = 2
+		amount = int64(transaction.Fee)
+	} else if amountDifference > 0 {
+		//Received
+		direction = 1
+		for _, credit := range transaction.MyOutputs {
+			amount += int64(credit.Amount)
+		}
+	} else {
+		//Sent
+		direction = 0
+		for _, debit := range transaction.MyInputs {
+			amount += int64(debit.PreviousAmount)
+		}
+		for _, credit := range transaction.MyOutputs {
+			amount -= int64(credit.Amount)
+		}
+		amount -= int64(transaction.Fee)
+	}
+	return direction, amount
+}
+
 func (lw *LibWallet) GetTransactions(response GetTransactionsResponse) error {
+	return lw.GetFilteredTransactions(TxFilterAll, response)
+}
+
+// GetFilteredTransactions returns the wallet transactions matching one of the
+// TxFilter constants through response.
+func (lw *LibWallet) GetFilteredTransactions(filter int32, response GetTransactionsResponse) error {
 	ctx := contextWithShutdownCancel(context.Background())
 	var startBlock, endBlock *wallet.BlockIdentifier
 	transactions := make([]Transaction, 0)
 	rangeFn := func(block *wallet.Block) (bool, error) {
 		for _, transaction := range block.Transactions {
-			var inputAmounts int64
-			var outputAmounts int64
-			var amount int64
-			tempCredits := make([]TransactionCredit, len(transaction.MyOutputs))
-			for index, credit := range transaction.MyOutputs {
-				outputAmounts += int64(credit.Amount)
-				tempCredits[index] = TransactionCredit{
-					Index:    int32(credit.Index),
-					Account:  int32(credit.Account),
-					Internal: credit.Internal,
-					Amount:   int64(credit.Amount),
-					Address:  credit.Address.String()}
-			}
-			tempDebits := make([]TransactionDebit, len(transaction.MyInputs))
-			for index, debit := range transaction.MyInputs {
-				inputAmounts += int64(debit.PreviousAmount)
-				tempDebits[index] = TransactionDebit{
-					Index:           int32(debit.Index),
-					PreviousAccount: int32(debit.PreviousAccount),
-					PreviousAmount:  int64(debit.PreviousAmount),
-					AccountName:     lw.GetAccountName(int32(debit.PreviousAccount))}
-			}
-			var direction int32
-			amountDifference := outputAmounts - inputAmounts
-			if amountDifference < 0 && (float64(transaction.Fee) == math.Abs(float64(amountDifference))) {
-				//Transfered
-				direction = 2
-				amount = int64(transaction.Fee)
-			} else if amountDifference > 0 {
-				//Received
-				direction = 1
-				for _, credit := range transaction.MyOutputs {
-					amount += int64(credit.Amount)
-				}
-			} else {
-				//Sent
-				direction = 0
-				for _, debit := range transaction.MyInputs {
-					amount += int64(debit.PreviousAmount)
-				}
-				for _, credit := range transaction.MyOutputs {
-					amount -= int64(credit.Amount)
-				}
-				amount -= int64(transaction.Fee)
+			if !txMatchesFilter(transaction.Type, filter) {
+				continue
 			}
-			var height int32 = -1
-			if block.Header != nil {
-				height = int32(block.Header.Height)
-			}
-			tempTransaction := Transaction{
-				Fee:       int64(transaction.Fee),
-				Hash:      fmt.Sprintf("%02x", reverse(transaction.Hash[:])),
-				Timestamp: transaction.Timestamp,
-				Type:      transactionType(transaction.Type),
-				Credits:   &tempCredits,
-				Amount:    amount,
-				Height:    height,
-				Direction: direction,
-				Debits:    &tempDebits}
+			tempTransaction := lw.parseTxSummary(&transaction, block.Header)
 			transactions = append(transactions, tempTransaction)
 		}
 		select {
@@ -739,169 +2036,1755 @@ func (lw *LibWallet) GetTransactions(response GetTransactionsResponse) error {
 		}
 	}
 	err := lw.wallet.GetTransactions(rangeFn, startBlock, endBlock)
+	if err != nil {
+		// Partial results are not passed on, so that an interrupted scan
+		// is not presented as the complete history.
+		log.Error(err)
+		result, _ := json.Marshal(getTransactionsResponse{ErrorOccurred: true, ErrorMessage: err.Error()})
+		response.OnResult(string(result))
+		return err
+	}
 	result, _ := json.Marshal(getTransactionsResponse{ErrorOccurred: false, Transactions: transactions})
 	response.OnResult(string(result))
-	return err
+	return nil
 }
 
-func (lw *LibWallet) DecodeTransaction(txHash []byte) (string, error) {
-	hash, err := chainhash.NewHash(txHash)
+// StreamTransactions passes each wallet transaction to listener as soon as it
+// is read, rather than collecting the whole history before returning it.
+func (lw *LibWallet) StreamTransactions(listener StreamTransactionsResponse) error {
+	ctx := contextWithShutdownCancel(context.Background())
+	rangeFn := func(block *wallet.Block) (bool, error) {
+		for i := range block.Transactions {
+			tempTransaction := lw.parseTxSummary(&block.Transactions[i], block.Header)
+			result, err := json.Marshal(tempTransaction)
+			if err != nil {
+				return true, err
+			}
+			listener.OnTransaction(string(result))
+		}
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		default:
+			return false, nil
+		}
+	}
+	err := lw.wallet.GetTransactions(rangeFn, nil, nil)
 	if err != nil {
 		log.Error(err)
-		return "", err
 	}
-	txSummary, _, _, err := lw.wallet.TransactionSummary(hash)
+	return err
+}
+
+// TransactionsForAddress returns the wallet transactions that pay to or spend
+// from an address.  An empty list is returned for an unused address.
+func (lw *LibWallet) TransactionsForAddress(address string) (string, error) {
+	addr, err := decodeAddress(address, lw.chainParams)
 	if err != nil {
 		log.Error(err)
 		return "", err
 	}
-	serializedTx := txSummary.Transaction
-	var mtx wire.MsgTx
-	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	encodedAddr := addr.EncodeAddress()
+
+	// Transactions are ranged over in block order, so every credit to the
+	// address is recorded before the transaction spending it is seen.
+	credited := make(map[wire.OutPoint]struct{})
+	transactions := make([]Transaction, 0)
+	rangeFn := func(block *wallet.Block) (bool, error) {
+		for _, transaction := range block.Transactions {
+			matched := false
+			for _, credit := range transaction.MyOutputs {
+				if credit.Address.EncodeAddress() != encodedAddr {
+					continue
+				}
+				op := wire.OutPoint{Hash: *transaction.Hash, Index: credit.Index}
+				credited[op] = struct{}{}
+				matched = true
+			}
+			if !matched && len(transaction.MyInputs) > 0 {
+				var mtx wire.MsgTx
+				err := mtx.Deserialize(bytes.NewReader(transaction.Transaction))
+				if err != nil {
+					return true, err
+				}
+				for _, debit := range transaction.MyInputs {
+					prevOut := mtx.TxIn[debit.Index].PreviousOutPoint
+					op := wire.OutPoint{Hash: prevOut.Hash, Index: prevOut.Index}
+					if _, ok := credited[op]; ok {
+						matched = true
+						break
+					}
+				}
+			}
+			if !matched {
+				continue
+			}
+			transactions = append(transactions, lw.parseTxSummary(&transaction, block.Header))
+		}
+		return false, nil
+	}
+	err = lw.wallet.GetTransactions(rangeFn, nil, nil)
 	if err != nil {
 		log.Error(err)
 		return "", err
 	}
-
-	var tx = DecodedTransaction{
-		Hash:     fmt.Sprintf("%02x", reverse(hash[:])),
-		Type:     transactionType(wallet.TxTransactionType(&mtx)),
-		Version:  int32(mtx.Version),
-		LockTime: int32(mtx.LockTime),
-		Expiry:   int32(mtx.Expiry),
-		Inputs:   decodeTxInputs(&mtx),
-		Outputs:  decodeTxOutputs(&mtx, lw.chainParams),
-	}
-	result, _ := json.Marshal(tx)
+	result, _ := json.Marshal(transactions)
 	return string(result), nil
 }
 
-func decodeTxInputs(mtx *wire.MsgTx) []DecodedInput {
-	inputs := make([]DecodedInput, len(mtx.TxIn))
-	for i, txIn := range mtx.TxIn {
-
-		inputs[i] = DecodedInput{
-			PreviousTransactionHash:  fmt.Sprintf("%02x", reverse(txIn.PreviousOutPoint.Hash[:])),
-			PreviousTransactionIndex: int32(txIn.PreviousOutPoint.Index),
-			Sequence:                 int32(txIn.Sequence),
-			AmountIn:                 txIn.ValueIn,
-			BlockHeight:              int32(txIn.BlockHeight),
-			BlockIndex:               int32(txIn.BlockIndex),
-		}
+// SpenderOfOutput returns the hash of the wallet transaction spending the
+// output at outputIndex of the transaction txHash, or an empty string if the
+// wallet has not seen it spent.
+func (lw *LibWallet) SpenderOfOutput(txHash []byte, outputIndex int32) (string, error) {
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		log.Error(err)
+		return "", err
 	}
-	return inputs
+	outpoint := wire.OutPoint{Hash: *hash, Index: uint32(outputIndex)}
+	spender, err := lw.spenderOfOutput(&outpoint)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	if spender == nil {
+		return "", nil
+	}
+	return spender.Hash.String(), nil
 }
 
-func decodeTxOutputs(mtx *wire.MsgTx, chainParams *chaincfg.Params) []DecodedOutput {
-	outputs := make([]DecodedOutput, len(mtx.TxOut))
-	txType := stake.DetermineTxType(mtx)
-	for i, v := range mtx.TxOut {
-
-		var addrs []dcrutil.Address
-		var encodedAddrs []string
-		if (txType == stake.TxTypeSStx) && (stake.IsStakeSubmissionTxOut(i)) {
-			addr, err := stake.AddrFromSStxPkScrCommitment(v.PkScript,
-				chainParams)
+// spenderOfOutput returns the wallet transaction spending outpoint, or nil if
+// the wallet has not seen it spent.
+func (lw *LibWallet) spenderOfOutput(outpoint *wire.OutPoint) (*wallet.TransactionSummary, error) {
+	var spender *wallet.TransactionSummary
+	rangeFn := func(block *wallet.Block) (bool, error) {
+		for i := range block.Transactions {
+			transaction := &block.Transactions[i]
+			if len(transaction.MyInputs) == 0 {
+				continue
+			}
+			var mtx wire.MsgTx
+			err := mtx.Deserialize(bytes.NewReader(transaction.Transaction))
 			if err != nil {
-				encodedAddrs = []string{fmt.Sprintf(
-					"[error] failed to decode ticket "+
-						"commitment addr output for tx hash "+
-						"%v, output idx %v", mtx.TxHash(), i)}
-			} else {
-				encodedAddrs = []string{addr.EncodeAddress()}
+				return true, err
 			}
-		} else {
-			// Ignore the error here since an error means the script
-			// couldn't parse and there is no additional information
-			// about it anyways.
-			_, addrs, _, _ = txscript.ExtractPkScriptAddrs(
-				v.Version, v.PkScript, chainParams)
-			encodedAddrs = make([]string, len(addrs))
-			for j, addr := range addrs {
-				encodedAddrs[j] = addr.EncodeAddress()
+			for _, debit := range transaction.MyInputs {
+				prevOut := mtx.TxIn[debit.Index].PreviousOutPoint
+				if prevOut.Hash == outpoint.Hash && prevOut.Index == outpoint.Index {
+					spender = transaction
+					return true, nil
+				}
 			}
 		}
-
-		outputs[i] = DecodedOutput{
-			Index:     int32(i),
-			Value:     v.Value,
-			Version:   int32(v.Version),
-			Addresses: encodedAddrs,
-		}
-	}
-
-	return outputs
-}
-
-func reverse(hash []byte) []byte {
-	for i := 0; i < len(hash)/2; i++ {
-		j := len(hash) - i - 1
-		hash[i], hash[j] = hash[j], hash[i]
-	}
-	return hash
-}
-
-func transactionType(txType wallet.TransactionType) string {
-	switch txType {
-	case wallet.TransactionTypeCoinbase:
-		return "COINBASE"
-	case wallet.TransactionTypeTicketPurchase:
-		return "TICKET_PURCHASE"
-	case wallet.TransactionTypeVote:
-		return "VOTE"
-	case wallet.TransactionTypeRevocation:
-		return "REVOCATION"
-	default:
-		return "REGULAR"
+		return false, nil
 	}
-}
-
-func (lw *LibWallet) GetBestBlock() int32 {
-	_, height := lw.wallet.MainChainTip()
-	return height
-}
-
-func (lw *LibWallet) GetBestBlockTimeStamp() int64 {
-	_, height := lw.wallet.MainChainTip()
-	identifier := wallet.NewBlockIdentifierFromHeight(height)
-	info, err := lw.wallet.BlockInfo(identifier)
+	err := lw.wallet.GetTransactions(rangeFn, nil, nil)
 	if err != nil {
-		log.Error(err)
-		return 0
+		return nil, err
 	}
-	return info.Timestamp
+	return spender, nil
 }
 
-func (lw *LibWallet) PublishUnminedTransactions() error {
-	if lw.netBackend == nil {
-		return errors.New("wallet is not associated with a consensus server RPC client")
+// TransactionCount returns the number of wallet transactions with a
+// direction, as reported in Transaction.Direction, or of all transactions
+// when direction is negative.
+func (lw *LibWallet) TransactionCount(direction int32) (int32, error) {
+	var count int32
+	rangeFn := func(block *wallet.Block) (bool, error) {
+		for i := range block.Transactions {
+			if direction >= 0 {
+				txDir, _ := txDirection(&block.Transactions[i])
+				if txDir != direction {
+					continue
+				}
+			}
+			count++
+		}
+		return false, nil
 	}
-	err := lw.wallet.PublishUnminedTransactions(contextWithShutdownCancel(context.Background()), lw.netBackend)
-	return err
-}
-
-func (lw *LibWallet) SpendableForAccount(account int32, requiredConfirmations int32) (int64, error) {
-	bals, err := lw.wallet.CalculateAccountBalance(uint32(account), requiredConfirmations)
+	err := lw.wallet.GetTransactions(rangeFn, nil, nil)
 	if err != nil {
 		log.Error(err)
 		return 0, err
 	}
-	return int64(bals.Spendable), nil
+	return count, nil
 }
 
-func (lw *LibWallet) AddressForAccount(account int32) (string, error) {
-	var callOpts []wallet.NextAddressCallOption
-	callOpts = append(callOpts, wallet.WithGapPolicyWrap())
-
-	addr, err := lw.wallet.NewExternalAddress(uint32(account), callOpts...)
+// WouldReuseAddress reports whether the wallet has previously received to or
+// sent to an address, so that a reuse warning can be shown before sending.
+func (lw *LibWallet) WouldReuseAddress(address string) (bool, error) {
+	addr, err := decodeAddress(address, lw.chainParams)
 	if err != nil {
 		log.Error(err)
-		return "", err
+		return false, err
 	}
-	return addr.EncodeAddress(), nil
-}
+	encodedAddr := addr.EncodeAddress()
+
+	used := false
+	rangeFn := func(block *wallet.Block) (bool, error) {
+		for _, transaction := range block.Transactions {
+			for _, credit := range transaction.MyOutputs {
+				if credit.Address.EncodeAddress() == encodedAddr {
+					used = true
+					return true, nil
+				}
+			}
+			// Only transactions spending wallet funds were sent by
+			// the wallet.
+			if len(transaction.MyInputs) == 0 {
+				continue
+			}
+			var mtx wire.MsgTx
+			err := mtx.Deserialize(bytes.NewReader(transaction.Transaction))
+			if err != nil {
+				return true, err
+			}
+			for _, txOut := range mtx.TxOut {
+				_, addrs, _, _ := txscript.ExtractPkScriptAddrs(txOut.Version,
+					txOut.PkScript, lw.chainParams)
+				for _, a := range addrs {
+					if a.EncodeAddress() == encodedAddr {
+						used = true
+						return true, nil
+					}
+				}
+			}
+		}
+		return false, nil
+	}
+	err = lw.wallet.GetTransactions(rangeFn, nil, nil)
+	if err != nil {
+		log.Error(err)
+		return false, err
+	}
+	return used, nil
+}
+
+// ExportStakingCSV returns a CSV record of the wallet's staking activity
+// between two block heights, for tax reporting.  Each vote or revocation mined
+// in the range is listed with the ticket it spent, and tickets purchased in
+// the range that have not voted are listed on their own.  The reward is the
+// amount returned by the vote or revocation less the ticket price, and the
+// net gain additionally subtracts the fee paid to buy the ticket.
+func (lw *LibWallet) ExportStakingCSV(startHeight, endHeight int32) (string, error) {
+	if startHeight < 0 || endHeight < startHeight {
+		return "", errors.E(errors.Invalid, "invalid block range")
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"Ticket Hash", "Purchase Date", "Ticket Price", "Ticket Fee",
+		"Vote Hash", "Vote Date", "Reward", "Net Gain"})
+
+	formatDate := func(timestamp int64) string {
+		return time.Unix(timestamp, 0).UTC().Format("2006-01-02 15:04:05")
+	}
+	formatAmount := func(amount int64) string {
+		return strconv.FormatFloat(dcrutil.Amount(amount).ToCoin(), 'f', 8, 64)
+	}
+	ticketRecord := func(ticket *wallet.TransactionSummary) ([]string, int64, error) {
+		var mtx wire.MsgTx
+		err := mtx.Deserialize(bytes.NewReader(ticket.Transaction))
+		if err != nil {
+			return nil, 0, err
+		}
+		price := mtx.TxOut[0].Value
+		return []string{ticket.Hash.String(), formatDate(ticket.Timestamp),
+			formatAmount(price), formatAmount(int64(ticket.Fee))}, price, nil
+	}
+
+	// Tickets purchased in the range, removed again once their vote or
+	// revocation is seen.
+	var unspentTickets []*chainhash.Hash
+	ticketRecords := make(map[chainhash.Hash][]string)
+
+	rangeFn := func(block *wallet.Block) (bool, error) {
+		for i := range block.Transactions {
+			transaction := &block.Transactions[i]
+			switch transaction.Type {
+			case wallet.TransactionTypeTicketPurchase:
+				record, _, err := ticketRecord(transaction)
+				if err != nil {
+					return true, err
+				}
+				unspentTickets = append(unspentTickets, transaction.Hash)
+				ticketRecords[*transaction.Hash] = record
+
+			case wallet.TransactionTypeVote, wallet.TransactionTypeRevocation:
+				var mtx wire.MsgTx
+				err := mtx.Deserialize(bytes.NewReader(transaction.Transaction))
+				if err != nil {
+					return true, err
+				}
+				// Votes spend the ticket in their second input after the
+				// stakebase, revocations in their only input.
+				ticketHash := mtx.TxIn[0].PreviousOutPoint.Hash
+				if transaction.Type == wallet.TransactionTypeVote {
+					ticketHash = mtx.TxIn[1].PreviousOutPoint.Hash
+				}
+				ticket, _, _, err := lw.wallet.TransactionSummary(&ticketHash)
+				if err != nil {
+					return true, err
+				}
+				record, price, err := ticketRecord(ticket)
+				if err != nil {
+					return true, err
+				}
+				delete(ticketRecords, ticketHash)
+
+				var returned int64
+				for _, credit := range transaction.MyOutputs {
+					returned += int64(credit.Amount)
+				}
+				reward := returned - price
+				record = append(record, transaction.Hash.String(),
+					formatDate(transaction.Timestamp), formatAmount(reward),
+					formatAmount(reward-int64(ticket.Fee)))
+				w.Write(record)
+			}
+		}
+		return false, nil
+	}
+	startBlock := wallet.NewBlockIdentifierFromHeight(startHeight)
+	endBlock := wallet.NewBlockIdentifierFromHeight(endHeight)
+	err := lw.wallet.GetTransactions(rangeFn, startBlock, endBlock)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	for _, hash := range unspentTickets {
+		record, ok := ticketRecords[*hash]
+		if !ok {
+			continue
+		}
+		w.Write(append(record, "", "", "", ""))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Error(err)
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// VerifyWalletIntegrity runs read-only consistency checks over the wallet
+// database and returns a report of any issues found.  It checks that the
+// account index resolves in both directions, that account balances can be
+// computed, that the main chain tip is known, and that every stored
+// transaction decodes to its hash with credits and debits that match the
+// transaction and the address index.
+func (lw *LibWallet) VerifyWalletIntegrity() (string, error) {
+	report := IntegrityReport{Issues: make([]string, 0)}
+	addIssue := func(format string, args ...interface{}) {
+		report.Issues = append(report.Issues, fmt.Sprintf(format, args...))
+	}
+
+	resp, err := lw.wallet.Accounts()
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	for _, a := range resp.Accounts {
+		report.AccountsChecked++
+		number, err := lw.wallet.AccountNumber(a.AccountName)
+		if err != nil {
+			addIssue("account %d: name %q does not resolve: %v", a.AccountNumber, a.AccountName, err)
+		} else if number != a.AccountNumber {
+			addIssue("account %d: name %q resolves to account %d", a.AccountNumber, a.AccountName, number)
+		}
+		_, err = lw.wallet.CalculateAccountBalance(a.AccountNumber, 0)
+		if err != nil {
+			addIssue("account %d: balance cannot be calculated: %v", a.AccountNumber, err)
+		}
+	}
+
+	tipHash, tipHeight := lw.wallet.MainChainTip()
+	_, err = lw.wallet.BlockInfo(wallet.NewBlockIdentifierFromHash(&tipHash))
+	if err != nil {
+		addIssue("main chain tip %v (height %d) is unknown: %v", &tipHash, tipHeight, err)
+	}
+
+	rangeFn := func(block *wallet.Block) (bool, error) {
+		for i := range block.Transactions {
+			transaction := &block.Transactions[i]
+			report.TransactionsChecked++
+			var mtx wire.MsgTx
+			err := mtx.Deserialize(bytes.NewReader(transaction.Transaction))
+			if err != nil {
+				addIssue("transaction %v cannot be decoded: %v", transaction.Hash, err)
+				continue
+			}
+			if txHash := mtx.TxHash(); txHash != *transaction.Hash {
+				addIssue("transaction %v is stored with hash %v", &txHash, transaction.Hash)
+			}
+			for _, debit := range transaction.MyInputs {
+				if int(debit.Index) >= len(mtx.TxIn) {
+					addIssue("transaction %v: debit of missing input %d", transaction.Hash, debit.Index)
+				}
+			}
+			for _, credit := range transaction.MyOutputs {
+				if int(credit.Index) >= len(mtx.TxOut) {
+					addIssue("transaction %v: credit of missing output %d", transaction.Hash, credit.Index)
+					continue
+				}
+				info, err := lw.wallet.AddressInfo(credit.Address)
+				if err != nil {
+					addIssue("transaction %v: credited address %v is not indexed: %v",
+						transaction.Hash, credit.Address, err)
+					continue
+				}
+				if info.Account() != credit.Account {
+					addIssue("transaction %v: credit to account %d but address %v belongs to account %d",
+						transaction.Hash, credit.Account, credit.Address, info.Account())
+				}
+			}
+		}
+		return false, nil
+	}
+	err = lw.wallet.GetTransactions(rangeFn, nil, nil)
+	if err != nil {
+		addIssue("transaction history cannot be read: %v", err)
+	}
+
+	result, _ := json.Marshal(report)
+	return string(result), nil
+}
+
+// DecodeTransaction decodes a wallet transaction.  The decoded fee is -1 when
+// the amount of any of its inputs is unknown.
+func (lw *LibWallet) DecodeTransaction(txHash []byte) (string, error) {
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	txSummary, _, _, err := lw.wallet.TransactionSummary(hash)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	serializedTx := txSummary.Transaction
+	var mtx wire.MsgTx
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	// Stored transactions do not necessarily record their input amounts,
+	// but the amounts spent from the wallet's own outputs are known.
+	for _, debit := range txSummary.MyInputs {
+		if int(debit.Index) < len(mtx.TxIn) {
+			mtx.TxIn[debit.Index].ValueIn = int64(debit.PreviousAmount)
+		}
+	}
+	fee := transactionFee(&mtx)
+
+	var tx = DecodedTransaction{
+		Hash:     fmt.Sprintf("%02x", reverse(hash[:])),
+		Type:     transactionType(wallet.TxTransactionType(&mtx)),
+		Version:  int32(mtx.Version),
+		LockTime: int32(mtx.LockTime),
+		Expiry:   int32(mtx.Expiry),
+		Size:     int32(mtx.SerializeSize()),
+		Fee:      fee,
+		Inputs:   decodeTxInputs(&mtx),
+		Outputs:  decodeTxOutputs(&mtx, lw.chainParams),
+	}
+	result, _ := json.Marshal(tx)
+	return string(result), nil
+}
+
+// TransactionFeeRate returns the fee rate in atoms/kB paid by a wallet
+// transaction, computed from its fee and serialized size.
+func (lw *LibWallet) TransactionFeeRate(txHash []byte) (int64, error) {
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	txSummary, _, _, err := lw.wallet.TransactionSummary(hash)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	var mtx wire.MsgTx
+	err = mtx.Deserialize(bytes.NewReader(txSummary.Transaction))
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	fee := transactionFee(&mtx)
+	if fee < 0 {
+		return 0, errors.E(errors.NotExist, "transaction input amounts are unknown")
+	}
+	return fee * 1000 / int64(mtx.SerializeSize()), nil
+}
+
+// transactionFee returns the fee paid by mtx, or -1 when the amount of any of
+// its inputs is unknown.
+func transactionFee(mtx *wire.MsgTx) int64 {
+	var totalIn, totalOut int64
+	for _, txIn := range mtx.TxIn {
+		if txIn.ValueIn == wire.NullValueIn {
+			return -1
+		}
+		totalIn += txIn.ValueIn
+	}
+	for _, txOut := range mtx.TxOut {
+		totalOut += txOut.Value
+	}
+	return totalIn - totalOut
+}
+
+// AbandonTicketPurchase removes an unmined ticket purchase of the wallet from
+// the wallet, freeing the outputs it spent.  The transaction may still be
+// mined if it has already been relayed to the network.
+func (lw *LibWallet) AbandonTicketPurchase(ticketHash []byte) error {
+	hash, err := chainhash.NewHash(ticketHash)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	txSummary, _, blockHash, err := lw.wallet.TransactionSummary(hash)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if txSummary.Type != wallet.TransactionTypeTicketPurchase {
+		return errors.E(errors.Invalid, "transaction is not a ticket purchase")
+	}
+	if blockHash != nil {
+		return errors.E(errors.Invalid, "ticket purchase is already mined")
+	}
+	err = lw.wallet.AbandonTransaction(hash)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// StakeDifficultyHistory returns the stake difficulty, the ticket price, of
+// every stake difficulty window starting between startHeight and endHeight,
+// read from the headers of the main chain.
+func (lw *LibWallet) StakeDifficultyHistory(startHeight, endHeight int32) (string, error) {
+	_, tipHeight := lw.wallet.MainChainTip()
+	if endHeight > tipHeight {
+		endHeight = tipHeight
+	}
+	if startHeight < 0 || startHeight > endHeight {
+		return "", errors.E(errors.Invalid, "invalid height range")
+	}
+
+	window := int32(lw.chainParams.StakeDiffWindowSize)
+	height := (startHeight + window - 1) / window * window
+	history := make([]StakeDifficulty, 0)
+	for ; height <= endHeight; height += window {
+		info, err := lw.wallet.BlockInfo(wallet.NewBlockIdentifierFromHeight(height))
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		var header wire.BlockHeader
+		err = header.FromBytes(info.Header)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		history = append(history, StakeDifficulty{
+			Height:          height,
+			Timestamp:       info.Timestamp,
+			StakeDifficulty: header.SBits,
+		})
+	}
+	result, _ := json.Marshal(history)
+	return string(result), nil
+}
+
+// ExpectedStakeReward returns the vote subsidy a ticket bought now is
+// expected to earn, from the subsidy schedule at the height the ticket is
+// expected to vote: after maturing and an average wait of a ticket pool size
+// in blocks.  The ticket price is returned along with the reward when voting
+// and is not included.
+func (lw *LibWallet) ExpectedStakeReward() (int64, error) {
+	_, tipHeight := lw.wallet.MainChainTip()
+	if tipHeight == 0 {
+		return 0, errors.E(errors.NotExist, "chain state is unavailable")
+	}
+	params := lw.chainParams
+	voteHeight := int64(tipHeight) + int64(params.TicketMaturity) + int64(params.TicketPoolSize)
+	subsidyCache := blockchain.NewSubsidyCache(voteHeight, params)
+	return blockchain.CalcStakeVoteSubsidy(subsidyCache, voteHeight, params), nil
+}
+
+// EstimateTicketVoteTime gives a best-effort estimate of the number of blocks
+// and seconds until a ticket votes.  Once live, each block draws
+// TicketsPerBlock tickets from the live ticket pool, so a ticket is expected
+// to be called after PoolSize / TicketsPerBlock blocks unless it expires
+// first.  The pool size is read from the header of the wallet's tip block,
+// falling back to the targeted pool size when it is not recorded.  Tickets
+// the wallet has seen voted or revoked are reported with no estimate.
+func (lw *LibWallet) EstimateTicketVoteTime(ticketHash []byte) (string, error) {
+	hash, err := chainhash.NewHash(ticketHash)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	txSummary, confs, _, err := lw.wallet.TransactionSummary(hash)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	if txSummary.Type != wallet.TransactionTypeTicketPurchase {
+		return "", errors.E(errors.Invalid, "transaction is not a ticket purchase")
+	}
+
+	// The ticket is spent by a vote or revocation through its stake
+	// submission output.
+	spender, err := lw.spenderOfOutput(&wire.OutPoint{Hash: *hash, Index: 0, Tree: wire.TxTreeStake})
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	if spender != nil {
+		estimate := TicketVoteEstimate{
+			TicketHash: hash.String(),
+			Status:     "voted",
+		}
+		if spender.Type == wallet.TransactionTypeRevocation {
+			estimate.Status = "revoked"
+		}
+		result, _ := json.Marshal(estimate)
+		return string(result), nil
+	}
+
+	params := lw.chainParams
+	poolSize := int32(params.TicketPoolSize) * int32(params.TicketsPerBlock)
+	tipHash, _ := lw.wallet.MainChainTip()
+	info, err := lw.wallet.BlockInfo(wallet.NewBlockIdentifierFromHash(&tipHash))
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	var header wire.BlockHeader
+	err = header.FromBytes(info.Header)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	if header.PoolSize > 0 {
+		poolSize = int32(header.PoolSize)
+	}
+
+	blocksUntilLive := int32(params.TicketMaturity) + 1 - confs
+	if blocksUntilLive < 0 {
+		blocksUntilLive = 0
+	}
+	blocksUntilExpiry := blocksUntilLive + int32(params.TicketExpiry)
+	if blocksUntilLive == 0 {
+		blocksUntilExpiry -= confs - int32(params.TicketMaturity) - 1
+	}
+	if blocksUntilExpiry < 0 {
+		blocksUntilExpiry = 0
+	}
+	expectedBlocks := blocksUntilLive + poolSize/int32(params.TicketsPerBlock)
+	if expectedBlocks > blocksUntilExpiry {
+		expectedBlocks = blocksUntilExpiry
+	}
+
+	status := "immature"
+	switch {
+	case blocksUntilExpiry == 0:
+		status = "expired"
+	case blocksUntilLive == 0:
+		status = "live"
+	}
+	estimate := TicketVoteEstimate{
+		TicketHash:              hash.String(),
+		Status:                  status,
+		Live:                    status == "live",
+		PoolSize:                poolSize,
+		BlocksUntilLive:         blocksUntilLive,
+		BlocksUntilExpiry:       blocksUntilExpiry,
+		ExpectedBlocksUntilVote: expectedBlocks,
+		ExpectedTimeUntilVote:   int64(expectedBlocks) * int64(params.TargetTimePerBlock.Seconds()),
+	}
+	result, _ := json.Marshal(estimate)
+	return string(result), nil
+}
+
+func decodeTxInputs(mtx *wire.MsgTx) []DecodedInput {
+	inputs := make([]DecodedInput, len(mtx.TxIn))
+	for i, txIn := range mtx.TxIn {
+
+		inputs[i] = DecodedInput{
+			PreviousTransactionHash:  fmt.Sprintf("%02x", reverse(txIn.PreviousOutPoint.Hash[:])),
+			PreviousTransactionIndex: int32(txIn.PreviousOutPoint.Index),
+			Sequence:                 int32(txIn.Sequence),
+			AmountIn:                 txIn.ValueIn,
+			BlockHeight:              int32(txIn.BlockHeight),
+			BlockIndex:               int32(txIn.BlockIndex),
+		}
+	}
+	return inputs
+}
+
+func decodeTxOutputs(mtx *wire.MsgTx, chainParams *chaincfg.Params) []DecodedOutput {
+	outputs := make([]DecodedOutput, len(mtx.TxOut))
+	txType := stake.DetermineTxType(mtx)
+	for i, v := range mtx.TxOut {
+
+		var addrs []dcrutil.Address
+		var encodedAddrs []string
+		var scriptType string
+		if (txType == stake.TxTypeSStx) && (stake.IsStakeSubmissionTxOut(i)) {
+			// Ticket commitments are nulldata scripts to the script
+			// classifier, so label them explicitly.
+			scriptType = "sstxcommitment"
+			addr, err := stake.AddrFromSStxPkScrCommitment(v.PkScript,
+				chainParams)
+			if err != nil {
+				encodedAddrs = []string{fmt.Sprintf(
+					"[error] failed to decode ticket "+
+						"commitment addr output for tx hash "+
+						"%v, output idx %v", mtx.TxHash(), i)}
+			} else {
+				encodedAddrs = []string{addr.EncodeAddress()}
+			}
+		} else {
+			// Ignore the error here since an error means the script
+			// couldn't parse and there is no additional information
+			// about it anyways.
+			var class txscript.ScriptClass
+			class, addrs, _, _ = txscript.ExtractPkScriptAddrs(
+				v.Version, v.PkScript, chainParams)
+			scriptType = class.String()
+			encodedAddrs = make([]string, len(addrs))
+			for j, addr := range addrs {
+				encodedAddrs[j] = addr.EncodeAddress()
+			}
+		}
+
+		outputs[i] = DecodedOutput{
+			Index:      int32(i),
+			Value:      v.Value,
+			Version:    int32(v.Version),
+			Addresses:  encodedAddrs,
+			ScriptType: scriptType,
+		}
+	}
+
+	return outputs
+}
+
+func reverse(hash []byte) []byte {
+	for i := 0; i < len(hash)/2; i++ {
+		j := len(hash) - i - 1
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	return hash
+}
+
+func transactionType(txType wallet.TransactionType) string {
+	switch txType {
+	case wallet.TransactionTypeCoinbase:
+		return "COINBASE"
+	case wallet.TransactionTypeTicketPurchase:
+		return "TICKET_PURCHASE"
+	case wallet.TransactionTypeVote:
+		return "VOTE"
+	case wallet.TransactionTypeRevocation:
+		return "REVOCATION"
+	default:
+		return "REGULAR"
+	}
+}
+
+func (lw *LibWallet) GetBestBlock() int32 {
+	_, height := lw.wallet.MainChainTip()
+	return height
+}
+
+func (lw *LibWallet) GetBestBlockTimeStamp() (int64, error) {
+	_, height := lw.wallet.MainChainTip()
+	identifier := wallet.NewBlockIdentifierFromHeight(height)
+	info, err := lw.wallet.BlockInfo(identifier)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return info.Timestamp, nil
+}
+
+// SyncMempool adds the unconfirmed transactions relevant to the wallet from
+// the consensus server's mempool, firing the usual transaction notifications
+// for any that were missed.
+//
+// Only RPC network backends are supported.  The SPV syncer keeps its peer
+// connections private, so mempool contents cannot be requested on demand
+// over them; an error of kind Invalid is returned while syncing over SPV.
+// Unconfirmed transactions still reach SPV wallets through the inventory
+// announcements of their peers, just not retroactively.
+func (lw *LibWallet) SyncMempool() error {
+	lw.mu.Lock()
+	chainClient := lw.rpcClient
+	backendAvailable := lw.backendAvailable
+	lw.mu.Unlock()
+	if chainClient == nil {
+		if backendAvailable {
+			return errors.E(errors.Invalid, "mempool sync is not supported over SPV")
+		}
+		return errors.E(errors.Invalid, "mempool sync requires a consensus server RPC client")
+	}
+
+	hashes, err := chainClient.GetRawMempool(dcrjson.GRMAll)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	for _, hash := range hashes {
+		tx, err := chainClient.GetRawTransaction(hash)
+		if err != nil {
+			// The transaction may have been mined or evicted since
+			// the mempool was listed.
+			log.Debugf("Unable to fetch mempool transaction %v: %v", hash, err)
+			continue
+		}
+		err = lw.wallet.AcceptMempoolTx(tx.MsgTx())
+		if err != nil {
+			log.Errorf("Unable to process mempool transaction %v: %v", hash, err)
+		}
+	}
+	return nil
+}
+
+func (lw *LibWallet) PublishUnminedTransactions() error {
+	if lw.netBackend == nil {
+		return errors.New("wallet is not associated with a consensus server RPC client")
+	}
+	err := lw.wallet.PublishUnminedTransactions(contextWithShutdownCancel(context.Background()), lw.netBackend)
+	return err
+}
+
+// RebroadcastTransaction publishes an unmined wallet transaction again over
+// the wallet's network backend.
+func (lw *LibWallet) RebroadcastTransaction(txHash []byte) error {
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	txSummary, _, blockHash, err := lw.wallet.TransactionSummary(hash)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if blockHash != nil {
+		return errors.E(errors.Invalid, "transaction is already mined")
+	}
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(txSummary.Transaction))
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	_, err = lw.publishWithRetry(&msgTx, txSummary.Transaction)
+	return err
+}
+
+// PublishUnminedTransactionsWithListener rebroadcasts every unmined wallet
+// transaction one at a time, notifying listener of whether each one was
+// accepted by the network.
+func (lw *LibWallet) PublishUnminedTransactionsWithListener(listener PublishTransactionListener) error {
+	n, err := lw.wallet.NetworkBackend()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	txs, err := lw.wallet.UnminedTransactions()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	ctx := contextWithShutdownCancel(context.Background())
+	for _, tx := range txs {
+		hash := tx.TxHash()
+		err := n.PublishTransactions(ctx, tx)
+		if err != nil {
+			log.Errorf("Failed to publish transaction %v: %v", &hash, err)
+			listener.OnTransactionRejected(hash.String(), err)
+			continue
+		}
+		listener.OnTransactionPublished(hash.String())
+	}
+	return nil
+}
+
+func (lw *LibWallet) SpendableForAccount(account int32, requiredConfirmations int32) (int64, error) {
+	bals, err := lw.wallet.CalculateAccountBalance(uint32(account), requiredConfirmations)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return int64(bals.Spendable), nil
+}
+
+// UnspentOutputCount returns the number of spendable outputs in an account.
+func (lw *LibWallet) UnspentOutputCount(account int32, requiredConfirmations int32) (int32, error) {
+	outputs, err := lw.unspentOutputs(account, requiredConfirmations)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return int32(len(outputs)), nil
+}
+
+// SpendableExcluding returns the spendable balance of an account without the
+// excluded outputs, such as outputs the user has reserved for coin control.
+func (lw *LibWallet) SpendableExcluding(account int32, requiredConfirmations int32, excluded []UnspentOutput) (int64, error) {
+	outputs, err := lw.unspentOutputs(account, requiredConfirmations)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	excludedOutPoints := make(map[wire.OutPoint]struct{}, len(excluded))
+	for _, output := range excluded {
+		hash, err := chainhash.NewHashFromStr(output.TransactionHash)
+		if err != nil {
+			log.Error(err)
+			return 0, err
+		}
+		op := wire.OutPoint{Hash: *hash, Index: uint32(output.OutputIndex)}
+		excludedOutPoints[op] = struct{}{}
+	}
+	var spendable int64
+	for _, output := range outputs {
+		op := wire.OutPoint{Hash: output.OutPoint.Hash, Index: output.OutPoint.Index}
+		if _, ok := excludedOutPoints[op]; ok {
+			continue
+		}
+		spendable += output.Output.Value
+	}
+	return spendable, nil
+}
+
+// LockOutput reserves a wallet output so that it is not selected as an input
+// by ConstructTransaction, SendTransaction or any other automatic input
+// selection until it is unlocked.  Locks are not persisted and are cleared
+// when the wallet is closed.
+func (lw *LibWallet) LockOutput(txHash []byte, index int32) error {
+	op, err := lw.walletOutPoint(txHash, index)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	lw.wallet.LockOutpoint(*op)
+	return nil
+}
+
+// UnlockOutput releases an output reserved by LockOutput.
+func (lw *LibWallet) UnlockOutput(txHash []byte, index int32) error {
+	op, err := lw.walletOutPoint(txHash, index)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	lw.wallet.UnlockOutpoint(*op)
+	return nil
+}
+
+// ListLockedOutputs returns the outputs reserved by LockOutput.  The wallet
+// only records the outpoints of locked outputs, so the amount and account of
+// each output are read from the transaction that created it.
+func (lw *LibWallet) ListLockedOutputs() (string, error) {
+	locked := lw.wallet.LockedOutpoints()
+	outputs := make([]UnspentOutput, 0, len(locked))
+	for _, input := range locked {
+		hash, err := chainhash.NewHashFromStr(input.Txid)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		txSummary, _, _, err := lw.wallet.TransactionSummary(hash)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		output := UnspentOutput{
+			TransactionHash: input.Txid,
+			OutputIndex:     int32(input.Vout),
+			Tree:            int32(transactionTree(txSummary.Type)),
+		}
+		for _, credit := range txSummary.MyOutputs {
+			if credit.Index == input.Vout {
+				output.Amount = int64(credit.Amount)
+				output.Account = int32(credit.Account)
+				break
+			}
+		}
+		outputs = append(outputs, output)
+	}
+	result, _ := json.Marshal(outputs)
+	return string(result), nil
+}
+
+// walletOutPoint returns the outpoint of an output of a wallet transaction,
+// including the tree of the transaction.
+func (lw *LibWallet) walletOutPoint(txHash []byte, index int32) (*wire.OutPoint, error) {
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	txSummary, _, _, err := lw.wallet.TransactionSummary(hash)
+	if err != nil {
+		return nil, err
+	}
+	owned := false
+	for _, credit := range txSummary.MyOutputs {
+		if int32(credit.Index) == index {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return nil, errors.E(errors.Invalid, fmt.Sprintf("transaction %v has no wallet output %d", hash, index))
+	}
+	tree := transactionTree(txSummary.Type)
+	return &wire.OutPoint{Hash: *hash, Index: uint32(index), Tree: tree}, nil
+}
+
+// transactionTree returns the tree of the block a transaction of txType is
+// mined in.
+func transactionTree(txType wallet.TransactionType) int8 {
+	switch txType {
+	case wallet.TransactionTypeTicketPurchase, wallet.TransactionTypeVote, wallet.TransactionTypeRevocation:
+		return wire.TxTreeStake
+	}
+	return wire.TxTreeRegular
+}
+
+// AddressesWithBalance returns the addresses of an account holding unspent
+// outputs with the total value of the outputs at each address, largest
+// balance first.
+func (lw *LibWallet) AddressesWithBalance(account int32, requiredConfirmations int32) (string, error) {
+	outputs, err := lw.unspentOutputs(account, requiredConfirmations)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	balances := make(map[string]*AddressBalance)
+	for _, output := range outputs {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(output.Output.Version,
+			output.Output.PkScript, lw.chainParams)
+		if err != nil || len(addrs) != 1 {
+			// Outputs not paying to a single address, such as bare
+			// multisig outputs, have no address to attribute them to.
+			continue
+		}
+		encodedAddr := addrs[0].EncodeAddress()
+		balance, ok := balances[encodedAddr]
+		if !ok {
+			balance = &AddressBalance{Address: encodedAddr}
+			balances[encodedAddr] = balance
+		}
+		balance.Balance += output.Output.Value
+		balance.UnspentOutputs++
+	}
+
+	resp := make([]AddressBalance, 0, len(balances))
+	for _, balance := range balances {
+		resp = append(resp, *balance)
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		if resp[i].Balance != resp[j].Balance {
+			return resp[i].Balance > resp[j].Balance
+		}
+		return resp[i].Address < resp[j].Address
+	})
+	result, _ := json.Marshal(resp)
+	return string(result), nil
+}
+
+// ListUnspent returns the spendable unspent outputs of an account.
+func (lw *LibWallet) ListUnspent(account int32, requiredConfirmations int32) (string, error) {
+	outputs, err := lw.listUnspent(account, requiredConfirmations)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	result, _ := json.Marshal(outputs)
+	return string(result), nil
+}
+
+// ListAllUnspent returns the spendable unspent outputs of every account,
+// including the imported account.
+func (lw *LibWallet) ListAllUnspent(requiredConfirmations int32) (string, error) {
+	resp, err := lw.wallet.Accounts()
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	outputs := make([]UnspentOutput, 0)
+	for _, a := range resp.Accounts {
+		accountOutputs, err := lw.listUnspent(int32(a.AccountNumber), requiredConfirmations)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		outputs = append(outputs, accountOutputs...)
+	}
+	result, _ := json.Marshal(outputs)
+	return string(result), nil
+}
+
+func (lw *LibWallet) listUnspent(account int32, requiredConfirmations int32) ([]UnspentOutput, error) {
+	unspent, err := lw.unspentOutputs(account, requiredConfirmations)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]UnspentOutput, len(unspent))
+	for i, output := range unspent {
+		outputs[i] = UnspentOutput{
+			TransactionHash: output.OutPoint.Hash.String(),
+			OutputIndex:     int32(output.OutPoint.Index),
+			Tree:            int32(output.OutPoint.Tree),
+			Amount:          output.Output.Value,
+			Account:         account,
+		}
+	}
+	return outputs, nil
+}
+
+// unspentOutputs returns the outputs of an account that may be spent with the
+// given number of confirmations.
+func (lw *LibWallet) unspentOutputs(account int32, requiredConfirmations int32) ([]*wallet.TransactionOutput, error) {
+	policy := wallet.OutputSelectionPolicy{
+		Account:               uint32(account),
+		RequiredConfirmations: requiredConfirmations,
+	}
+	return lw.wallet.UnspentOutputs(policy)
+}
+
+// AccountExtendedPubKey returns the extended public key of an account along
+// with the next unused child index of its external and internal branches, so
+// that addresses can be derived outside of the wallet without reuse or gaps.
+func (lw *LibWallet) AccountExtendedPubKey(account int32) (string, error) {
+	key, err := lw.accountExtendedKey(account)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	result, _ := json.Marshal(key)
+	return string(result), nil
+}
+
+// WalletFingerprint returns an identifier of the wallet that does not change
+// over its lifetime: the hex encoded first four bytes of the hash160 of the
+// account 0 extended public key's public key, as used for BIP0032 key
+// fingerprints.  No private key material is involved.
+func (lw *LibWallet) WalletFingerprint() (string, error) {
+	xpub, err := lw.wallet.MasterPubKey(0)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	pubKey, err := xpub.ECPubKey()
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	fingerprint := dcrutil.Hash160(pubKey.SerializeCompressed())[:4]
+	return hex.EncodeToString(fingerprint), nil
+}
+
+// ExportAccountWatchingInfo returns the public data a watching-only wallet
+// needs to track an account: its extended public key and the next external
+// and internal address indexes, along with the network and account name.
+func (lw *LibWallet) ExportAccountWatchingInfo(account int32) (string, error) {
+	key, err := lw.accountExtendedKey(account)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	name, err := lw.wallet.AccountName(uint32(account))
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	info := AccountWatchingInfo{
+		Network:        lw.chainParams.Name,
+		AccountName:    name,
+		Account:        key.Account,
+		ExtendedPubKey: key.ExtendedPubKey,
+		ExternalIndex:  key.ExternalIndex,
+		InternalIndex:  key.InternalIndex,
+	}
+	result, _ := json.Marshal(info)
+	return string(result), nil
+}
+
+// CoinType returns the BIP0044 coin type the wallet derives its accounts
+// with, which is either the network's legacy coin type or its SLIP0044 coin
+// type as reported by NetworkParams.
+func (lw *LibWallet) CoinType() (int32, error) {
+	coinType, err := lw.wallet.CoinType()
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return int32(coinType), nil
+}
+
+// UpgradeToSLIP0044CoinType switches a wallet deriving its accounts with the
+// legacy coin type to the SLIP0044 coin type.  The wallet refuses the upgrade
+// once addresses of the legacy coin type have been used, so funds derived
+// under the legacy coin type stay recoverable.
+func (lw *LibWallet) UpgradeToSLIP0044CoinType() error {
+	err := lw.wallet.UpgradeToSLIP0044CoinType()
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+func (lw *LibWallet) accountExtendedKey(account int32) (*AccountExtendedKey, error) {
+	xpub, err := lw.wallet.MasterPubKey(uint32(account))
+	if err != nil {
+		return nil, err
+	}
+	extIndex, intIndex, err := lw.wallet.BIP0044BranchNextIndexes(uint32(account))
+	if err != nil {
+		return nil, err
+	}
+	return &AccountExtendedKey{
+		Account:        account,
+		ExtendedPubKey: xpub.String(),
+		ExternalIndex:  int32(extIndex),
+		InternalIndex:  int32(intIndex),
+	}, nil
+}
+
+// VoteVersionCompatible compares the vote version of the votes this wallet
+// casts with the stake version of the main chain tip and the latest agenda
+// version known for the network.  Voting is only possible when the wallet's
+// vote version is not behind the network's stake version.
+func (lw *LibWallet) VoteVersionCompatible() (string, error) {
+	walletVersion, _ := wallet.CurrentAgendas(lw.chainParams)
+
+	var latestVersion uint32
+	for version := range lw.chainParams.Deployments {
+		if version > latestVersion {
+			latestVersion = version
+		}
+	}
+
+	tipHash, _ := lw.wallet.MainChainTip()
+	info, err := lw.wallet.BlockInfo(wallet.NewBlockIdentifierFromHash(&tipHash))
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	var header wire.BlockHeader
+	err = header.FromBytes(info.Header)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	resp := VoteVersion{
+		WalletVoteVersion:       walletVersion,
+		NetworkStakeVersion:     header.StakeVersion,
+		LatestDeploymentVersion: latestVersion,
+		Compatible:              walletVersion >= header.StakeVersion,
+	}
+	result, _ := json.Marshal(resp)
+	return string(result), nil
+}
+
+// AccountBalanceAtHeight reconstructs the spendable balance of an account as
+// of a past block height by replaying the wallet's mined transactions up to
+// and including that height.  Funds locked in unspent tickets and coinbase or
+// stake outputs that were still immature at that height are excluded.
+func (lw *LibWallet) AccountBalanceAtHeight(account int32, height int32) (int64, error) {
+	_, tipHeight := lw.wallet.MainChainTip()
+	if height < 0 || height > tipHeight {
+		return 0, errors.E(errors.Invalid, fmt.Sprintf("height %d is outside the main chain (tip %d)",
+			height, tipHeight))
+	}
+
+	// Credits that may have been unspendable at height, keyed by outpoint
+	// so that they are forgotten once spent.
+	type heldCredit struct {
+		amount int64
+		height int32
+		ticket bool
+	}
+	held := make(map[wire.OutPoint]heldCredit)
+
+	var balance int64
+	rangeFn := func(block *wallet.Block) (bool, error) {
+		blockHeight := int32(block.Header.Height)
+		for _, transaction := range block.Transactions {
+			for _, credit := range transaction.MyOutputs {
+				if int32(credit.Account) != account {
+					continue
+				}
+				balance += int64(credit.Amount)
+				switch transaction.Type {
+				case wallet.TransactionTypeCoinbase, wallet.TransactionTypeTicketPurchase,
+					wallet.TransactionTypeVote, wallet.TransactionTypeRevocation:
+					op := wire.OutPoint{Hash: *transaction.Hash, Index: credit.Index}
+					held[op] = heldCredit{
+						amount: int64(credit.Amount),
+						height: blockHeight,
+						ticket: transaction.Type == wallet.TransactionTypeTicketPurchase && credit.Index == 0,
+					}
+				}
+			}
+			if len(transaction.MyInputs) == 0 {
+				continue
+			}
+			var mtx wire.MsgTx
+			err := mtx.Deserialize(bytes.NewReader(transaction.Transaction))
+			if err != nil {
+				return true, err
+			}
+			for _, debit := range transaction.MyInputs {
+				if int32(debit.PreviousAccount) != account {
+					continue
+				}
+				balance -= int64(debit.PreviousAmount)
+				prevOut := mtx.TxIn[debit.Index].PreviousOutPoint
+				delete(held, wire.OutPoint{Hash: prevOut.Hash, Index: prevOut.Index})
+			}
+		}
+		return false, nil
+	}
+	startBlock := wallet.NewBlockIdentifierFromHeight(0)
+	endBlock := wallet.NewBlockIdentifierFromHeight(height)
+	err := lw.wallet.GetTransactions(rangeFn, startBlock, endBlock)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	maturity := int32(lw.chainParams.CoinbaseMaturity)
+	for _, credit := range held {
+		if credit.ticket || height-credit.height+1 < maturity {
+			balance -= credit.amount
+		}
+	}
+	return balance, nil
+}
+
+func (lw *LibWallet) AddressForAccount(account int32) (string, error) {
+	return lw.AddressForAccountWithGapPolicy(account, GapPolicyWrap)
+}
+
+// Gap policies accepted by AddressForAccountWithGapPolicy.
+const (
+	GapPolicyWrap int32 = iota
+	GapPolicyError
+	GapPolicyIgnore
+)
+
+// AddressForAccountWithGapPolicy returns the next external address of an
+// account.  The gap policy decides what happens once the number of unused
+// addresses reaches the gap limit: GapPolicyWrap reuses addresses from the
+// start of the gap, GapPolicyError returns an error and GapPolicyIgnore
+// derives addresses past the gap limit.
+func (lw *LibWallet) AddressForAccountWithGapPolicy(account int32, gapPolicy int32) (string, error) {
+	var callOpts []wallet.NextAddressCallOption
+	switch gapPolicy {
+	case GapPolicyWrap:
+		callOpts = append(callOpts, wallet.WithGapPolicyWrap())
+	case GapPolicyError:
+		callOpts = append(callOpts, wallet.WithGapPolicyError())
+	case GapPolicyIgnore:
+		callOpts = append(callOpts, wallet.WithGapPolicyIgnore())
+	default:
+		return "", errors.E(errors.Invalid, fmt.Sprintf("unknown gap policy %d", gapPolicy))
+	}
+
+	addr, err := lw.wallet.NewExternalAddress(uint32(account), callOpts...)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// InternalAddressForAccount returns the next internal (change) address of an
+// account, for routing the change of a custom-built transaction back to the
+// wallet.
+func (lw *LibWallet) InternalAddressForAccount(account int32) (string, error) {
+	addr, err := lw.wallet.NewInternalAddress(uint32(account), wallet.WithGapPolicyWrap())
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
 
 func (lw *LibWallet) ConstructTransaction(destAddr string, amount int64, srcAccount int32, requiredConfirmations int32, sendAll bool) (*ConstructTxResponse, error) {
+	return lw.ConstructTimeLockedTransaction(destAddr, amount, srcAccount, requiredConfirmations, sendAll, 0, 0)
+}
+
+// ConstructTimeLockedTransaction constructs a transaction like
+// ConstructTransaction, additionally setting its lock time and expiry.  A
+// lockTime below txscript.LockTimeThreshold is a block height, otherwise it
+// is a unix timestamp.  A zero lockTime or expiry leaves the transaction
+// without a lock time or expiry respectively.
+func (lw *LibWallet) ConstructTimeLockedTransaction(destAddr string, amount int64, srcAccount int32, requiredConfirmations int32, sendAll bool, lockTime int32, expiry int32) (*ConstructTxResponse, error) {
+	return lw.constructTransaction(destAddr, amount, srcAccount, requiredConfirmations, sendAll, lockTime, expiry, -1)
+}
+
+// ConstructTransactionWithoutChange constructs a transaction paying amount to
+// destAddr that has no change output, which avoids revealing which output is
+// the sender's change.  When the selected inputs exceed the amount and fee,
+// up to maxExtraFee of the remainder is added to the fee instead of being
+// returned as change.  An errors.Policy error is returned when the remainder
+// is larger than maxExtraFee.
+func (lw *LibWallet) ConstructTransactionWithoutChange(destAddr string, amount int64, srcAccount int32, requiredConfirmations int32, maxExtraFee int64) (*ConstructTxResponse, error) {
+	if maxExtraFee < 0 {
+		return nil, errors.E(errors.Invalid, "maximum extra fee must not be negative")
+	}
+	return lw.constructTransaction(destAddr, amount, srcAccount, requiredConfirmations, false, 0, 0, maxExtraFee)
+}
+
+// constructTransaction constructs an unsigned transaction.  A negative
+// maxExtraFee allows a change output, otherwise any change up to maxExtraFee
+// is added to the fee.
+func (lw *LibWallet) constructTransaction(destAddr string, amount int64, srcAccount int32, requiredConfirmations int32, sendAll bool, lockTime int32, expiry int32, maxExtraFee int64) (*ConstructTxResponse, error) {
+	if !sendAll {
+		err := validateSendAmount(amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if lockTime < 0 {
+		return nil, errors.E(errors.Invalid, "lock time must not be negative")
+	}
+	if expiry < 0 {
+		return nil, errors.E(errors.Invalid, "expiry must not be negative")
+	}
+	_, height := lw.wallet.MainChainTip()
+	if expiry != 0 && expiry <= height+1 {
+		return nil, errors.E(errors.Invalid, fmt.Sprintf("expiry %d must be above the next block height %d",
+			expiry, height+1))
+	}
+	if lockTime != 0 && uint32(lockTime) < txscript.LockTimeThreshold && expiry != 0 && expiry <= lockTime {
+		return nil, errors.E(errors.Invalid, "transaction would expire before its lock time")
+	}
+
+	// output destination
+	addr, err := dcrutil.DecodeAddress(destAddr)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	version := txscript.DefaultScriptVersion
+
+	// pay output
+	outputs := make([]*wire.TxOut, 0)
+	var algo wallet.OutputSelectionAlgorithm = wallet.OutputSelectionAlgorithmAll
+	if !sendAll {
+		err = lw.checkMatureFunds(srcAccount, amount, requiredConfirmations)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		algo = wallet.OutputSelectionAlgorithmDefault
+		output := &wire.TxOut{
+			Value:    amount,
+			Version:  version,
+			PkScript: pkScript,
+		}
+		outputs = append(outputs, output)
+	}
+	feePerKb := txrules.DefaultRelayFeePerKb
+
+	// create tx
+	tx, err := lw.wallet.NewUnsignedTransaction(outputs, feePerKb, uint32(srcAccount),
+		requiredConfirmations, algo, nil)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	if maxExtraFee >= 0 && tx.ChangeIndex >= 0 {
+		change := tx.Tx.TxOut[tx.ChangeIndex]
+		if change.Value > maxExtraFee {
+			return nil, errors.E(errors.Policy, fmt.Sprintf("no input selection without change found; "+
+				"the change of %v exceeds the maximum extra fee", dcrutil.Amount(change.Value)))
+		}
+		tx.EstimatedSignedSerializeSize -= change.SerializeSize()
+		tx.Tx.TxOut = append(tx.Tx.TxOut[:tx.ChangeIndex], tx.Tx.TxOut[tx.ChangeIndex+1:]...)
+		tx.ChangeIndex = -1
+	}
+	if lockTime != 0 {
+		// The lock time is only enforced when at least one input is not
+		// finalized.
+		for _, txIn := range tx.Tx.TxIn {
+			txIn.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+		tx.Tx.LockTime = uint32(lockTime)
+	}
+	tx.Tx.Expiry = uint32(expiry)
+
+	var txBuf bytes.Buffer
+	txBuf.Grow(tx.Tx.SerializeSize())
+	err = tx.Tx.Serialize(&txBuf)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	var totalOutput dcrutil.Amount
+	for _, txOut := range outputs {
+		totalOutput += dcrutil.Amount(txOut.Value)
+	}
+	return &ConstructTxResponse{
+		TotalOutputAmount:         int64(totalOutput),
+		UnsignedTransaction:       txBuf.Bytes(),
+		TotalPreviousOutputAmount: int64(tx.TotalInput),
+		MinimumFee:                MinimumFee(int32(tx.EstimatedSignedSerializeSize)),
+		EstimatedSignedSize:       int32(tx.EstimatedSignedSerializeSize)}, nil
+}
+
+// ProjectedBalanceChange returns how publishing a constructed transaction,
+// such as the UnsignedTransaction of a ConstructTxResponse, changes the
+// balance of each wallet account it spends from or pays to.
+func (lw *LibWallet) ProjectedBalanceChange(serializedTx []byte, requiredConfirmations int32) (string, error) {
+	var mtx wire.MsgTx
+	err := mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	changes := make(map[uint32]*BalanceChange)
+	change := func(account uint32) *BalanceChange {
+		c, ok := changes[account]
+		if !ok {
+			c = &BalanceChange{Account: int32(account)}
+			changes[account] = c
+		}
+		return c
+	}
+	for _, txIn := range mtx.TxIn {
+		prevOut := &txIn.PreviousOutPoint
+		prevTx, _, _, err := lw.wallet.TransactionSummary(&prevOut.Hash)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		for _, credit := range prevTx.MyOutputs {
+			if credit.Index == prevOut.Index {
+				change(credit.Account).Spent += int64(credit.Amount)
+				break
+			}
+		}
+	}
+	for _, txOut := range mtx.TxOut {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.Version, txOut.PkScript, lw.chainParams)
+		if err != nil || len(addrs) != 1 {
+			continue
+		}
+		info, err := lw.wallet.AddressInfo(addrs[0])
+		if err != nil {
+			// Not a wallet address.
+			continue
+		}
+		change(info.Account()).Received += txOut.Value
+	}
+
+	resp := ProjectedBalanceChange{
+		Fee:      transactionFee(&mtx),
+		Accounts: make([]BalanceChange, 0, len(changes)),
+	}
+	for account, c := range changes {
+		bals, err := lw.wallet.CalculateAccountBalance(account, requiredConfirmations)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		c.AccountName = lw.accountName(c.Account)
+		c.Net = c.Received - c.Spent
+		c.CurrentSpendable = int64(bals.Spendable)
+		c.ProjectedSpendable = c.CurrentSpendable - c.Spent
+		resp.Accounts = append(resp.Accounts, *c)
+	}
+	sort.Slice(resp.Accounts, func(i, j int) bool {
+		return uint32(resp.Accounts[i].Account) < uint32(resp.Accounts[j].Account)
+	})
+	result, _ := json.Marshal(resp)
+	return string(result), nil
+}
+
+// MinimumFee returns the minimum fee the network relays a transaction of the
+// given serialized size for.
+func MinimumFee(serializeSize int32) int64 {
+	return int64(txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, int(serializeSize)))
+}
+
+// averageTxSize is the assumed average size of a regular transaction, used to
+// estimate how many transactions fit in a block.
+const averageTxSize = 400
+
+// EstimateConfirmationBlocks estimates the number of blocks until a
+// transaction paying feePerKb is mined.  Decred blocks are rarely full, so a
+// transaction paying at least the relay fee is normally mined in the next
+// block; when connected to a consensus server, the estimate grows with the
+// number of regular transactions waiting in its mempool.  An errors.Policy
+// error is returned for fee rates below the relay fee, as such transactions
+// are not relayed.
+func (lw *LibWallet) EstimateConfirmationBlocks(feePerKb int64) (int32, error) {
+	if feePerKb < int64(txrules.DefaultRelayFeePerKb) {
+		return 0, errors.E(errors.Policy, fmt.Sprintf("fee rate is below the relay fee of %v/kB",
+			txrules.DefaultRelayFeePerKb))
+	}
+
+	lw.mu.Lock()
+	chainClient := lw.rpcClient
+	lw.mu.Unlock()
+	if chainClient == nil {
+		return 1, nil
+	}
+	hashes, err := chainClient.GetRawMempool(dcrjson.GRMRegular)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	txsPerBlock := lw.chainParams.MaximumBlockSizes[0] / averageTxSize
+	return int32(1 + len(hashes)/txsPerBlock), nil
+}
+
+// p2pkhPkScriptSize is the size of a pay-to-pubkey-hash output script.
+const p2pkhPkScriptSize = 25
+
+// DustThreshold returns the smallest amount of a pay-to-pubkey-hash output
+// that is not considered dust at the fee rate feePerKb.  A feePerKb of zero
+// uses the default relay fee.
+func DustThreshold(feePerKb int64) int64 {
+	return dustThreshold(p2pkhPkScriptSize, feePerKb)
+}
+
+// DustThresholdForAddress returns the smallest amount of an output paying to
+// address that is not considered dust at the fee rate feePerKb, taking the
+// size of the address's output script into account.
+func (lw *LibWallet) DustThresholdForAddress(address string, feePerKb int64) (int64, error) {
+	addr, err := decodeAddress(address, lw.chainParams)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return dustThreshold(len(pkScript), feePerKb), nil
+}
+
+// dustThreshold inverts txrules.IsDustAmount, which considers an output dust
+// when amount*1000/(3*size) is below the fee rate, size being the serialized
+// size of the output plus the size of a redeeming input.
+func dustThreshold(scriptSize int, feePerKb int64) int64 {
+	if feePerKb <= 0 {
+		feePerKb = int64(txrules.DefaultRelayFeePerKb)
+	}
+	totalSize := int64(8 + 2 + wire.VarIntSerializeSize(uint64(scriptSize)) + scriptSize + 165)
+	return (3*totalSize*feePerKb + 999) / 1000
+}
+
+// validateSendAmount checks that an amount to send is positive and does not
+// exceed the total supply of coins.
+func validateSendAmount(amount int64) error {
+	if amount <= 0 {
+		return errors.E(errors.Invalid, "amount must be positive")
+	}
+	if amount > dcrutil.MaxAmount {
+		return errors.E(errors.Invalid, "amount exceeds the maximum amount")
+	}
+	return nil
+}
+
+// checkMatureFunds returns an error when amount can only be paid by also
+// spending immature coinbase or stake generation outputs.  These outputs are
+// never selected for spending regardless of the required confirmations.
+func (lw *LibWallet) checkMatureFunds(account int32, amount int64, requiredConfirmations int32) error {
+	bals, err := lw.wallet.CalculateAccountBalance(uint32(account), requiredConfirmations)
+	if err != nil {
+		return err
+	}
+	return checkMatureBalance(bals, amount)
+}
+
+// checkMatureBalance returns an error when amount exceeds the spendable
+// balance of bals but not the spendable and immature balances combined.
+// Amounts exceeding both are left to input selection to report.
+func checkMatureBalance(bals udb.Balances, amount int64) error {
+	spendable := int64(bals.Spendable)
+	if amount <= spendable {
+		return nil
+	}
+	immature := int64(bals.ImmatureCoinbaseRewards + bals.ImmatureStakeGeneration)
+	if amount <= spendable+immature {
+		return errors.E(errors.InsufficientBalance, "amount can only be paid with immature funds")
+	}
+	return nil
+}
+
+func (lw *LibWallet) RunGC() {
+	debug.FreeOSMemory()
+}
+
+func (lw *LibWallet) SendTransaction(privPass []byte, destAddr string, amount int64, srcAccount int32, requiredConfs int32, sendAll bool) ([]byte, error) {
+	tx, err := lw.sendTransaction(privPass, destAddr, amount, srcAccount, requiredConfs, sendAll)
+	if err != nil {
+		return nil, err
+	}
+	txHash := tx.TxHash()
+	return txHash[:], nil
+}
+
+// SendTransactionWithDetails sends a transaction like SendTransaction and
+// returns its hash along with the fee paid and its size.
+func (lw *LibWallet) SendTransactionWithDetails(privPass []byte, destAddr string, amount int64, srcAccount int32, requiredConfs int32, sendAll bool) (string, error) {
+	tx, err := lw.sendTransaction(privPass, destAddr, amount, srcAccount, requiredConfs, sendAll)
+	if err != nil {
+		return "", err
+	}
+	resp := SentTransaction{
+		Hash: tx.TxHash().String(),
+		Fee:  transactionFee(tx),
+		Size: int32(tx.SerializeSize()),
+	}
+	result, _ := json.Marshal(resp)
+	return string(result), nil
+}
+
+// sendTransaction signs and publishes a transaction paying amount, or the
+// whole balance of srcAccount when sendAll is set, to destAddr and returns the
+// published transaction.
+func (lw *LibWallet) sendTransaction(privPass []byte, destAddr string, amount int64, srcAccount int32, requiredConfs int32, sendAll bool) (*wire.MsgTx, error) {
+	defer zeroBytes(privPass)
+	_, err := lw.wallet.NetworkBackend()
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	if !sendAll {
+		err = validateSendAmount(amount)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// output destination
 	addr, err := dcrutil.DecodeAddress(destAddr)
 	if err != nil {
@@ -913,158 +3796,428 @@ func (lw *LibWallet) ConstructTransaction(destAddr string, amount int64, srcAcco
 		log.Error(err)
 		return nil, err
 	}
-	version := txscript.DefaultScriptVersion
 
 	// pay output
 	outputs := make([]*wire.TxOut, 0)
 	var algo wallet.OutputSelectionAlgorithm = wallet.OutputSelectionAlgorithmAll
 	if !sendAll {
+		err = lw.checkMatureFunds(srcAccount, amount, requiredConfs)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
 		algo = wallet.OutputSelectionAlgorithmDefault
 		output := &wire.TxOut{
 			Value:    amount,
-			Version:  version,
+			Version:  txscript.DefaultScriptVersion,
 			PkScript: pkScript,
 		}
 		outputs = append(outputs, output)
 	}
-	feePerKb := txrules.DefaultRelayFeePerKb
 
 	// create tx
-	tx, err := lw.wallet.NewUnsignedTransaction(outputs, feePerKb, uint32(srcAccount),
-		requiredConfirmations, algo, nil)
+	unsignedTx, err := lw.wallet.NewUnsignedTransaction(outputs, txrules.DefaultRelayFeePerKb, uint32(srcAccount),
+		requiredConfs, algo, nil)
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
 	var txBuf bytes.Buffer
-	txBuf.Grow(tx.Tx.SerializeSize())
-	err = tx.Tx.Serialize(&txBuf)
+	txBuf.Grow(unsignedTx.Tx.SerializeSize())
+	err = unsignedTx.Tx.Serialize(&txBuf)
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
-	var totalOutput dcrutil.Amount
-	for _, txOut := range outputs {
-		totalOutput += dcrutil.Amount(txOut.Value)
+
+	var tx wire.MsgTx
+	err = tx.Deserialize(bytes.NewReader(txBuf.Bytes()))
+	if err != nil {
+		log.Error(err)
+		//Bytes do not represent a valid raw transaction
+		return nil, err
 	}
-	return &ConstructTxResponse{
-		TotalOutputAmount:         int64(totalOutput),
-		UnsignedTransaction:       txBuf.Bytes(),
-		TotalPreviousOutputAmount: int64(tx.TotalInput),
-		EstimatedSignedSize:       int32(tx.EstimatedSignedSerializeSize)}, nil
+
+	_, err = lw.signAndPublish(privPass, &tx)
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
 }
 
-func (lw *LibWallet) RunGC() {
-	debug.FreeOSMemory()
+// SetStrictSigning sets whether transactions are only published when all of
+// their inputs were signed.  When strict, sending a transaction with inputs
+// the wallet could not sign fails with an error listing the input indexes
+// instead of publishing the transaction.
+func (lw *LibWallet) SetStrictSigning(strict bool) {
+	lw.mu.Lock()
+	lw.strictSigning = strict
+	lw.mu.Unlock()
 }
 
-func (lw *LibWallet) SendTransaction(privPass []byte, destAddr string, amount int64, srcAccount int32, requiredConfs int32, sendAll bool) ([]byte, error) {
-	n, err := lw.wallet.NetworkBackend()
+// signAndPublish unlocks the wallet with privPass to sign tx and publishes it
+// over the wallet's network backend.
+func (lw *LibWallet) signAndPublish(privPass []byte, tx *wire.MsgTx) (*chainhash.Hash, error) {
+	lw.mu.Lock()
+	strictSigning := lw.strictSigning
+	lw.mu.Unlock()
+	err := lw.signTransaction(privPass, tx, strictSigning)
+	if err != nil {
+		return nil, err
+	}
+
+	var serializedTransaction bytes.Buffer
+	serializedTransaction.Grow(tx.SerializeSize())
+	err = tx.Serialize(&serializedTransaction)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(serializedTransaction.Bytes()))
 	if err != nil {
+		//Invalid tx
 		log.Error(err)
 		return nil, err
 	}
+
+	return lw.publishWithRetry(&msgTx, serializedTransaction.Bytes())
+}
+
+// signTransaction unlocks the wallet with privPass to sign tx.  When
+// requireAllSigned is set, an error listing the inputs that could not be
+// signed is returned if any input is left unsigned.
+func (lw *LibWallet) signTransaction(privPass []byte, tx *wire.MsgTx, requireAllSigned bool) error {
+	lock := make(chan time.Time, 1)
 	defer func() {
-		for i := range privPass {
-			privPass[i] = 0
-		}
+		lock <- time.Time{}
 	}()
-	// output destination
-	addr, err := dcrutil.DecodeAddress(destAddr)
+
+	err := lw.wallet.Unlock(privPass, lock)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	var additionalPkScripts map[wire.OutPoint][]byte
+
+	invalidSigs, err := lw.wallet.SignTransaction(tx, txscript.SigHashAll, additionalPkScripts, nil, nil)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	invalidInputIndexes := make([]uint32, len(invalidSigs))
+	for i, e := range invalidSigs {
+		invalidInputIndexes[i] = e.InputIndex
+	}
+	if requireAllSigned && len(invalidInputIndexes) > 0 {
+		log.Errorf("Transaction inputs %v could not be signed", invalidInputIndexes)
+		return errors.E(errors.Policy, fmt.Sprintf("transaction inputs %v could not be signed",
+			invalidInputIndexes))
+	}
+	return nil
+}
+
+// PrepareSignedTransaction constructs and signs a transaction paying the
+// destinations, a JSON array of objects with an Address and an Amount,
+// without publishing it.  The returned details can be shown for review before
+// the signed transaction is published with PublishRawTransaction.
+func (lw *LibWallet) PrepareSignedTransaction(privPass []byte, destinations string, srcAccount int32, requiredConfs int32) (string, error) {
+	defer zeroBytes(privPass)
+	var dests []struct {
+		Address string
+		Amount  int64
+	}
+	err := json.Unmarshal([]byte(destinations), &dests)
+	if err != nil {
+		log.Error(err)
+		return "", errors.E(errors.Invalid, fmt.Sprintf("invalid destinations: %v", err))
+	}
+	if len(dests) == 0 {
+		return "", errors.E(errors.Invalid, "no destinations")
+	}
+
+	outputs := make([]*wire.TxOut, 0, len(dests))
+	var totalOutput int64
+	for _, dest := range dests {
+		err = validateSendAmount(dest.Amount)
+		if err != nil {
+			return "", err
+		}
+		addr, err := decodeAddress(dest.Address, lw.chainParams)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		outputs = append(outputs, &wire.TxOut{
+			Value:    dest.Amount,
+			Version:  txscript.DefaultScriptVersion,
+			PkScript: pkScript,
+		})
+		totalOutput += dest.Amount
+	}
+	err = lw.checkMatureFunds(srcAccount, totalOutput, requiredConfs)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	unsignedTx, err := lw.wallet.NewUnsignedTransaction(outputs, txrules.DefaultRelayFeePerKb, uint32(srcAccount),
+		requiredConfs, wallet.OutputSelectionAlgorithmDefault, nil)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	tx := unsignedTx.Tx
+	err = lw.signTransaction(privPass, tx, true)
+	if err != nil {
+		return "", err
+	}
+
+	var txBuf bytes.Buffer
+	txBuf.Grow(tx.SerializeSize())
+	err = tx.Serialize(&txBuf)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	var change int64
+	if unsignedTx.ChangeIndex >= 0 {
+		change = tx.TxOut[unsignedTx.ChangeIndex].Value
+	}
+	resp := PreparedTransaction{
+		Hash:              tx.TxHash().String(),
+		SignedTransaction: hex.EncodeToString(txBuf.Bytes()),
+		Fee:               int64(unsignedTx.TotalInput) - totalOutput - change,
+		TotalInput:        int64(unsignedTx.TotalInput),
+		TotalOutput:       totalOutput,
+		Change:            change,
+		Outputs:           decodeTxOutputs(tx, lw.chainParams),
+	}
+	result, _ := json.Marshal(resp)
+	return string(result), nil
+}
+
+// PublishRawTransaction publishes a hex encoded signed transaction, such as
+// one returned by PrepareSignedTransaction, and returns its hash.
+func (lw *LibWallet) PublishRawTransaction(signedTx string) ([]byte, error) {
+	serializedTx, err := hex.DecodeString(signedTx)
+	if err != nil {
+		log.Error(err)
+		return nil, errors.E(errors.Encoding, err)
+	}
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(serializedTx))
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
-	pkScript, err := txscript.PayToAddrScript(addr)
+	txHash, err := lw.publishWithRetry(&msgTx, serializedTx)
 	if err != nil {
-		log.Error(err)
 		return nil, err
 	}
+	return txHash[:], nil
+}
 
-	// pay output
-	outputs := make([]*wire.TxOut, 0)
-	var algo wallet.OutputSelectionAlgorithm = wallet.OutputSelectionAlgorithmAll
-	if !sendAll {
-		algo = wallet.OutputSelectionAlgorithmDefault
-		output := &wire.TxOut{
-			Value:    amount,
-			Version:  txscript.DefaultScriptVersion,
-			PkScript: pkScript,
-		}
-		outputs = append(outputs, output)
+// Default number of times and initial delay with which publishing a
+// transaction is retried after a transient error.
+const (
+	defaultPublishRetries = 2
+	defaultPublishBackoff = time.Second
+)
+
+// SetPublishRetry sets how many times publishing a transaction is retried
+// after a transient network error, such as a dropped peer, and the delay
+// before the first retry, which doubles with every further retry.
+// Rejections of the transaction itself are never retried.
+func (lw *LibWallet) SetPublishRetry(maxRetries int32, initialBackoffMillis int32) error {
+	if maxRetries < 0 || initialBackoffMillis < 0 {
+		return errors.E(errors.Invalid, "retries and backoff must not be negative")
 	}
+	lw.mu.Lock()
+	lw.publishRetries = int(maxRetries)
+	lw.publishBackoff = time.Duration(initialBackoffMillis) * time.Millisecond
+	lw.mu.Unlock()
+	return nil
+}
 
-	// create tx
-	unsignedTx, err := lw.wallet.NewUnsignedTransaction(outputs, txrules.DefaultRelayFeePerKb, uint32(srcAccount),
-		requiredConfs, algo, nil)
-	if err != nil {
-		log.Error(err)
-		return nil, err
+// isTransientPublishError returns whether a publish error was caused by the
+// connection to the network rather than by the transaction, so that
+// publishing again may succeed.
+func isTransientPublishError(err error) bool {
+	if errors.Is(errors.NoPeers, err) || errors.Is(errors.IO, err) {
+		return true
+	}
+	if err == context.DeadlineExceeded {
+		return true
 	}
+	_, ok := err.(net.Error)
+	return ok
+}
 
-	var txBuf bytes.Buffer
-	txBuf.Grow(unsignedTx.Tx.SerializeSize())
-	err = unsignedTx.Tx.Serialize(&txBuf)
+// publishWithRetry publishes a signed transaction, retrying with backoff
+// after transient errors.  When publishing fails, a transaction rejected by
+// the network is returned with the kind of the rejection, while transient
+// errors that persist through the retries are returned as errors.NoPeers.
+func (lw *LibWallet) publishWithRetry(msgTx *wire.MsgTx, serializedTx []byte) (*chainhash.Hash, error) {
+	lw.mu.Lock()
+	retries := lw.publishRetries
+	backoff := lw.publishBackoff
+	lw.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		// The network backend may have been cleared (e.g. after a block
+		// notification error) or replaced since the last attempt.
+		n, err := lw.wallet.NetworkBackend()
+		if err != nil || n == nil {
+			err = errors.E(errors.NoPeers, "network backend unavailable")
+		} else {
+			var txHash *chainhash.Hash
+			txHash, err = lw.wallet.PublishTransaction(msgTx, serializedTx, n)
+			if err == nil {
+				return txHash, nil
+			}
+		}
+		if !isTransientPublishError(err) {
+			log.Errorf("Transaction rejected: %v", err)
+			return nil, err
+		}
+		if attempt >= retries {
+			log.Errorf("Failed to publish transaction after %d attempts: %v", attempt+1, err)
+			return nil, errors.E(errors.NoPeers, fmt.Sprintf("transient error publishing transaction after %d attempts: %v",
+				attempt+1, err))
+		}
+		log.Warnf("Retrying publishing transaction after transient error: %v", err)
+		time.Sleep(backoff << uint(attempt))
+	}
+}
+
+// Sizes used to estimate the size of transactions redeeming P2PKH outputs.
+// These match the estimates made by the wallet when authoring transactions.
+const (
+	redeemP2PKHSigScriptSize = 1 + 73 + 1 + 33
+	redeemP2PKHInputSize     = 32 + 4 + 1 + 8 + 4 + 4 + 1 + redeemP2PKHSigScriptSize
+	p2pkhOutputSize          = 8 + 2 + 1 + 25
+)
+
+// estimateP2PKHTxSize estimates the signed size of a transaction spending
+// P2PKH inputs to P2PKH outputs.
+func estimateP2PKHTxSize(inputs, outputs int) int {
+	return 12 + 2*wire.VarIntSerializeSize(uint64(inputs)) +
+		wire.VarIntSerializeSize(uint64(outputs)) +
+		inputs*redeemP2PKHInputSize + outputs*p2pkhOutputSize
+}
+
+// ConsolidateOutputs spends up to maxInputs of the smallest spendable outputs
+// of an account to a new internal address of the same account, reducing the
+// number of inputs needed by future transactions.  The hash of the published
+// transaction is returned.
+func (lw *LibWallet) ConsolidateOutputs(privPass []byte, account int32, requiredConfs int32, maxInputs int32) ([]byte, error) {
+	defer zeroBytes(privPass)
+	if maxInputs < 2 {
+		return nil, errors.E(errors.Invalid, "at least two inputs are required to consolidate")
+	}
+	_, err := lw.wallet.NetworkBackend()
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
-	var tx wire.MsgTx
-	err = tx.Deserialize(bytes.NewReader(txBuf.Bytes()))
+	unspent, err := lw.unspentOutputs(account, requiredConfs)
 	if err != nil {
 		log.Error(err)
-		//Bytes do not represent a valid raw transaction
 		return nil, err
 	}
+	sort.Slice(unspent, func(i, j int) bool {
+		return unspent[i].Output.Value < unspent[j].Output.Value
+	})
+	if len(unspent) > int(maxInputs) {
+		unspent = unspent[:maxInputs]
+	}
+	if len(unspent) < 2 {
+		return nil, errors.E(errors.Invalid, "not enough outputs to consolidate")
+	}
 
-	lock := make(chan time.Time, 1)
-	defer func() {
-		lock <- time.Time{}
-	}()
-
-	err = lw.wallet.Unlock(privPass, lock)
+	addr, err := lw.wallet.NewInternalAddress(uint32(account), wallet.WithGapPolicyWrap())
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
-
-	var additionalPkScripts map[wire.OutPoint][]byte
-
-	invalidSigs, err := lw.wallet.SignTransaction(&tx, txscript.SigHashAll, additionalPkScripts, nil, nil)
+	pkScript, err := txscript.PayToAddrScript(addr)
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
-	invalidInputIndexes := make([]uint32, len(invalidSigs))
-	for i, e := range invalidSigs {
-		invalidInputIndexes[i] = e.InputIndex
+	tx := wire.NewMsgTx()
+	var totalInput dcrutil.Amount
+	for _, output := range unspent {
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: output.OutPoint,
+			Sequence:         wire.MaxTxInSequenceNum,
+			ValueIn:          output.Output.Value,
+		})
+		totalInput += dcrutil.Amount(output.Output.Value)
 	}
-
-	var serializedTransaction bytes.Buffer
-	serializedTransaction.Grow(tx.SerializeSize())
-	err = tx.Serialize(&serializedTransaction)
-	if err != nil {
-		log.Error(err)
-		return nil, err
+	fee := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb,
+		estimateP2PKHTxSize(len(tx.TxIn), 1))
+	outputValue := totalInput - fee
+	if txrules.IsDustAmount(outputValue, len(pkScript), txrules.DefaultRelayFeePerKb) {
+		return nil, errors.E(errors.InsufficientBalance, "consolidated amount would be dust")
 	}
+	tx.AddTxOut(wire.NewTxOut(int64(outputValue), pkScript))
 
-	var msgTx wire.MsgTx
-	err = msgTx.Deserialize(bytes.NewReader(serializedTransaction.Bytes()))
+	txHash, err := lw.signAndPublish(privPass, tx)
 	if err != nil {
-		//Invalid tx
-		log.Error(err)
 		return nil, err
 	}
+	return txHash[:], nil
+}
+
+func (lw *LibWallet) GetAccounts(requiredConfirmations int32) (string, error) {
+	return lw.getAccounts(requiredConfirmations, nil)
+}
 
-	txHash, err := lw.wallet.PublishTransaction(&msgTx, serializedTransaction.Bytes(), n)
+// GetAccountsWithConfirmationTiers returns the accounts like GetAccounts,
+// additionally including the balance of each account for every number of
+// required confirmations in the comma separated confirmationTiers.
+func (lw *LibWallet) GetAccountsWithConfirmationTiers(requiredConfirmations int32, confirmationTiers string) (string, error) {
+	var tiers []int32
+	for _, tier := range strings.Split(confirmationTiers, ",") {
+		tier = strings.TrimSpace(tier)
+		if tier == "" {
+			continue
+		}
+		confs, err := strconv.ParseInt(tier, 10, 32)
+		if err != nil || confs < 0 {
+			return "", errors.E(errors.Invalid, fmt.Sprintf("invalid confirmation tier %q", tier))
+		}
+		tiers = append(tiers, int32(confs))
+	}
+	return lw.getAccounts(requiredConfirmations, tiers)
+}
 
-	return txHash[:], err
+func accountBalance(bals udb.Balances) Balance {
+	return Balance{
+		Total:                   int64(bals.Total),
+		Spendable:               int64(bals.Spendable),
+		ImmatureReward:          int64(bals.ImmatureCoinbaseRewards),
+		ImmatureStakeGeneration: int64(bals.ImmatureStakeGeneration),
+		LockedByTickets:         int64(bals.LockedByTickets),
+		VotingAuthority:         int64(bals.VotingAuthority),
+		UnConfirmed:             int64(bals.Unconfirmed),
+	}
 }
 
-func (lw *LibWallet) GetAccounts(requiredConfirmations int32) (string, error) {
+func (lw *LibWallet) getAccounts(requiredConfirmations int32, tiers []int32) (string, error) {
 	resp, err := lw.wallet.Accounts()
 	if err != nil {
 		log.Error("Unable to get accounts from wallet")
@@ -1080,20 +4233,28 @@ func (lw *LibWallet) GetAccounts(requiredConfirmations int32) (string, error) {
 			return "", fmt.Errorf("Unable to calculate balance for account %v",
 				a.AccountNumber)
 		}
-		balance := Balance{
-			Total:                   int64(bals.Total),
-			Spendable:               int64(bals.Spendable),
-			ImmatureReward:          int64(bals.ImmatureCoinbaseRewards),
-			ImmatureStakeGeneration: int64(bals.ImmatureStakeGeneration),
-			LockedByTickets:         int64(bals.LockedByTickets),
-			VotingAuthority:         int64(bals.VotingAuthority),
-			UnConfirmed:             int64(bals.Unconfirmed),
+		balance := accountBalance(bals)
+		var tieredBalances *[]TieredBalance
+		if len(tiers) > 0 {
+			tiered := make([]TieredBalance, len(tiers))
+			for j, confs := range tiers {
+				bals, err := lw.wallet.CalculateAccountBalance(a.AccountNumber, confs)
+				if err != nil {
+					log.Errorf("Unable to calculate balance for account %v",
+						a.AccountNumber)
+					return "", fmt.Errorf("Unable to calculate balance for account %v",
+						a.AccountNumber)
+				}
+				tiered[j] = TieredBalance{RequiredConfirmations: confs, Balance: accountBalance(bals)}
+			}
+			tieredBalances = &tiered
 		}
 		accounts[i] = Account{
 			Number:           int32(a.AccountNumber),
 			Name:             a.AccountName,
 			TotalBalance:     int64(a.TotalBalance),
 			Balance:          &balance,
+			TieredBalances:   tieredBalances,
 			ExternalKeyCount: int32(a.LastUsedExternalIndex + 20),
 			InternalKeyCount: int32(a.LastUsedInternalIndex + 20),
 			ImportedKeyCount: int32(a.ImportedKeyCount),
@@ -1110,12 +4271,133 @@ func (lw *LibWallet) GetAccounts(requiredConfirmations int32) (string, error) {
 	return string(result), nil
 }
 
+// AccountGapStatus returns, for every HD account, how many addresses have
+// been returned past the last used address of each branch.  An account at
+// the gap limit has handed out as many unused addresses as the wallet
+// watches, so funds sent to addresses beyond them would not be found without
+// rediscovering the account with a larger gap limit.
+func (lw *LibWallet) AccountGapStatus() (string, error) {
+	resp, err := lw.wallet.Accounts()
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	statuses := make([]GapStatus, 0, len(resp.Accounts))
+	for _, a := range resp.Accounts {
+		if a.AccountNumber == udb.ImportedAddrAccount {
+			continue
+		}
+		extIndex, intIndex, err := lw.wallet.BIP0044BranchNextIndexes(a.AccountNumber)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		// The last used index is ^uint32(0) when no address of the
+		// branch is used, which wraps to zero here.
+		unusedExternal := int32(extIndex - (a.LastUsedExternalIndex + 1))
+		unusedInternal := int32(intIndex - (a.LastUsedInternalIndex + 1))
+		statuses = append(statuses, GapStatus{
+			Account:                 int32(a.AccountNumber),
+			AccountName:             a.AccountName,
+			UnusedExternalAddresses: unusedExternal,
+			UnusedInternalAddresses: unusedInternal,
+			AtGapLimit:              unusedExternal >= addressGapLimit || unusedInternal >= addressGapLimit,
+		})
+	}
+	result, _ := json.Marshal(statuses)
+	return string(result), nil
+}
+
+// GetAccountsLight returns the numbers and names of the wallet's accounts,
+// ending with the imported account, without calculating their balances.
+func (lw *LibWallet) GetAccountsLight() (string, error) {
+	accounts := make([]AccountName, 0)
+	for account := uint32(0); account < udb.ImportedAddrAccount; account++ {
+		name, err := lw.wallet.AccountName(account)
+		if errors.Is(errors.NotExist, err) {
+			break
+		}
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		accounts = append(accounts, AccountName{Number: int32(account), Name: name})
+	}
+	name, err := lw.wallet.AccountName(udb.ImportedAddrAccount)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	accounts = append(accounts, AccountName{Number: int32(udb.ImportedAddrAccount), Name: name})
+
+	result, _ := json.Marshal(accounts)
+	return string(result), nil
+}
+
+// TotalBalance returns the balance of all accounts, with the imported
+// account's coins reported separately from the HD accounts.
+func (lw *LibWallet) TotalBalance(requiredConfirmations int32) (string, error) {
+	resp, err := lw.wallet.Accounts()
+	if err != nil {
+		log.Error("Unable to get accounts from wallet")
+		return "", errors.New("Unable to get accounts from wallet")
+	}
+	var total TotalBalance
+	for _, a := range resp.Accounts {
+		bals, err := lw.wallet.CalculateAccountBalance(a.AccountNumber, requiredConfirmations)
+		if err != nil {
+			log.Errorf("Unable to calculate balance for account %v",
+				a.AccountNumber)
+			return "", fmt.Errorf("Unable to calculate balance for account %v",
+				a.AccountNumber)
+		}
+		if a.AccountNumber == udb.ImportedAddrAccount {
+			total.ImportedTotal += int64(bals.Total)
+			total.ImportedSpendable += int64(bals.Spendable)
+		} else {
+			total.HDTotal += int64(bals.Total)
+			total.HDSpendable += int64(bals.Spendable)
+		}
+	}
+	total.Total = total.HDTotal + total.ImportedTotal
+	total.Spendable = total.HDSpendable + total.ImportedSpendable
+	result, _ := json.Marshal(total)
+	return string(result), nil
+}
+
+// WalletBalance returns the balance of the whole wallet broken down into
+// spendable funds, funds locked by tickets and immature rewards.
+func (lw *LibWallet) WalletBalance(requiredConfirmations int32) (string, error) {
+	resp, err := lw.wallet.Accounts()
+	if err != nil {
+		log.Error("Unable to get accounts from wallet")
+		return "", errors.New("Unable to get accounts from wallet")
+	}
+	var balance Balance
+	for _, a := range resp.Accounts {
+		bals, err := lw.wallet.CalculateAccountBalance(a.AccountNumber, requiredConfirmations)
+		if err != nil {
+			log.Errorf("Unable to calculate balance for account %v",
+				a.AccountNumber)
+			return "", fmt.Errorf("Unable to calculate balance for account %v",
+				a.AccountNumber)
+		}
+		balance.Total += int64(bals.Total)
+		balance.Spendable += int64(bals.Spendable)
+		balance.ImmatureReward += int64(bals.ImmatureCoinbaseRewards)
+		balance.ImmatureStakeGeneration += int64(bals.ImmatureStakeGeneration)
+		balance.LockedByTickets += int64(bals.LockedByTickets)
+		balance.VotingAuthority += int64(bals.VotingAuthority)
+		balance.UnConfirmed += int64(bals.Unconfirmed)
+	}
+	result, _ := json.Marshal(balance)
+	return string(result), nil
+}
+
 func (lw *LibWallet) NextAccount(accountName string, privPass []byte) bool {
 	lock := make(chan time.Time, 1)
 	defer func() {
-		for i := range privPass {
-			privPass[i] = 0
-		}
+		zeroBytes(privPass)
 		lock <- time.Time{} // send matters, not the value
 	}()
 	err := lw.wallet.Unlock(privPass, lock)
@@ -1132,8 +4414,140 @@ func (lw *LibWallet) NextAccount(accountName string, privPass []byte) bool {
 	return true
 }
 
+// CreateAccount creates an account and returns its number along with its
+// first receive address.
+func (lw *LibWallet) CreateAccount(accountName string, privPass []byte) (string, error) {
+	lock := make(chan time.Time, 1)
+	defer func() {
+		zeroBytes(privPass)
+		lock <- time.Time{} // send matters, not the value
+	}()
+	err := lw.wallet.Unlock(privPass, lock)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	account, err := lw.wallet.NextAccount(accountName)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	addr, err := lw.wallet.NewExternalAddress(account, wallet.WithGapPolicyWrap())
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	resp := CreatedAccount{
+		Number:  int32(account),
+		Name:    accountName,
+		Address: addr.EncodeAddress(),
+	}
+	result, _ := json.Marshal(resp)
+	return string(result), nil
+}
+
+// ImportPrivateKeys imports a batch of WIF encoded private keys into the
+// imported account.  Keys that fail to import are reported in the result
+// along with their index instead of failing the whole batch.  If response is
+// not nil, a single rescan from scanFrom is started once all keys are
+// imported, reporting to response as Rescan does; no keys are imported when
+// another rescan is already running.
+func (lw *LibWallet) ImportPrivateKeys(privPass []byte, wifs []string, scanFrom int32, response BlockScanResponse) (string, error) {
+	lock := make(chan time.Time, 1)
+	defer func() {
+		zeroBytes(privPass)
+		lock <- time.Time{} // send matters, not the value
+	}()
+
+	rescan := response != nil
+	if rescan {
+		_, err := lw.wallet.NetworkBackend()
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+		// Reserve the rescan before importing so that the keys are not
+		// imported without the rescan their funds depend on.
+		err = lw.reserveRescan()
+		if err != nil {
+			return "", err
+		}
+	}
+	rescanStarted := false
+	defer func() {
+		if rescan && !rescanStarted {
+			lw.releaseRescan()
+		}
+	}()
+
+	err := lw.wallet.Unlock(privPass, lock)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	resp := ImportPrivateKeysResponse{
+		Imported: make([]ImportedKey, 0, len(wifs)),
+		Failures: make([]ImportKeyFailure, 0),
+	}
+	for i, encoded := range wifs {
+		address, err := lw.importPrivateKey(strings.TrimSpace(encoded))
+		if err != nil {
+			log.Errorf("Failed to import key %d: %v", i, err)
+			resp.Failures = append(resp.Failures, ImportKeyFailure{
+				Index:        int32(i),
+				ErrorMessage: err.Error(),
+			})
+			continue
+		}
+		resp.Imported = append(resp.Imported, ImportedKey{
+			Index:   int32(i),
+			Address: address,
+		})
+	}
+
+	if rescan && len(resp.Imported) > 0 {
+		rescanStarted = true
+		go lw.runRescan(scanFrom, response)
+	}
+
+	result, _ := json.Marshal(resp)
+	return string(result), nil
+}
+
+func (lw *LibWallet) importPrivateKey(encoded string) (string, error) {
+	wif, err := dcrutil.DecodeWIF(encoded)
+	if err != nil {
+		return "", err
+	}
+	if !wif.IsForNet(lw.chainParams) {
+		return "", errors.E(errors.Invalid, fmt.Sprintf("key is not intended for use on %v",
+			lw.chainParams.Name))
+	}
+	return lw.wallet.ImportPrivateKey(wif)
+}
+
+// RenameAccount renames an account.  The imported account cannot be renamed
+// and no account can take the name of another account or the reserved name
+// of the imported account.
 func (lw *LibWallet) RenameAccount(accountNumber int32, newName string) error {
-	err := lw.wallet.RenameAccount(uint32(accountNumber), newName)
+	if uint32(accountNumber) == udb.ImportedAddrAccount {
+		return errors.E(errors.Invalid, "the imported account cannot be renamed")
+	}
+	if newName == udb.ImportedAddrAccountName {
+		return errors.E(errors.Exist, fmt.Sprintf("account name %q is reserved", newName))
+	}
+	existing, err := lw.wallet.AccountNumber(newName)
+	if err == nil && existing != uint32(accountNumber) {
+		return errors.E(errors.Exist, fmt.Sprintf("account name %q is already in use", newName))
+	}
+	if err != nil && !errors.Is(errors.NotExist, err) {
+		log.Error(err)
+		return err
+	}
+	err = lw.wallet.RenameAccount(uint32(accountNumber), newName)
 	return err
 }
 
@@ -1208,3 +4622,72 @@ func (lw *LibWallet) CallJSONRPC(method string, args string, address string, use
 	}
 	return "", nil
 }
+
+// CallJSONRPCBatch sends several JSON-RPC commands in a single request.
+// requests is a JSON array of objects with a method and an array of params,
+// and the result is a JSON array with a JSONRPCBatchResult for each request,
+// in the order of the requests.
+func (lw *LibWallet) CallJSONRPCBatch(requests string, address string, username string, password string, caCert string) (string, error) {
+	var batch []struct {
+		Method string        `json:"method"`
+		Params []interface{} `json:"params"`
+	}
+	err := json.Unmarshal([]byte(requests), &batch)
+	if err != nil {
+		log.Error(err)
+		return "", errors.E(errors.Invalid, fmt.Sprintf("invalid batch requests: %v", err))
+	}
+	if len(batch) == 0 {
+		return "", errors.E(errors.Invalid, "no requests in batch")
+	}
+
+	marshalledCmds := make([]json.RawMessage, len(batch))
+	for i, request := range batch {
+		cmd, err := dcrjson.NewCmd(request.Method, request.Params...)
+		if err != nil {
+			log.Errorf("%s command: %v\n", request.Method, err)
+			return "", err
+		}
+		// Request ids start at 1 and match the request's position in
+		// the batch.
+		marshalledCmds[i], err = dcrjson.MarshalCmd("1.0", i+1, cmd)
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+	}
+	marshalledJSON, err := json.Marshal(marshalledCmds)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	resps, err := sendBatchPostRequest(marshalledJSON, address, username, password, caCert)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+
+	results := make([]JSONRPCBatchResult, len(batch))
+	for i := range results {
+		results[i].ErrorMessage = "no response"
+	}
+	for _, resp := range resps {
+		if resp.ID == nil {
+			continue
+		}
+		id, ok := (*resp.ID).(float64)
+		if !ok || id < 1 || int(id) > len(results) {
+			continue
+		}
+		result := &results[int(id)-1]
+		if resp.Error != nil {
+			result.ErrorMessage = resp.Error.Error()
+			continue
+		}
+		result.Result = string(resp.Result)
+		result.ErrorMessage = ""
+	}
+	result, _ := json.Marshal(results)
+	return string(result), nil
+}
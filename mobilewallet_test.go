@@ -0,0 +1,188 @@
+package mobilewallet
+
+import (
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrwallet/errors"
+	"github.com/decred/dcrwallet/spv"
+	"github.com/decred/dcrwallet/wallet/udb"
+)
+
+func TestCheckMatureBalance(t *testing.T) {
+	bals := udb.Balances{
+		Spendable:               10e8,
+		ImmatureCoinbaseRewards: 3e8,
+		ImmatureStakeGeneration: 2e8,
+	}
+	tests := []struct {
+		name     string
+		bals     udb.Balances
+		amount   int64
+		immature bool
+	}{
+		{"below spendable", bals, 5e8, false},
+		{"equal to spendable", bals, 10e8, false},
+		{"needs immature coinbase", bals, 12e8, true},
+		{"needs all immature funds", bals, 15e8, true},
+		{"exceeds all funds", bals, 15e8 + 1, false},
+		{"immature funds only", udb.Balances{ImmatureStakeGeneration: 2e8}, 1e8, true},
+		{"no funds", udb.Balances{}, 1e8, false},
+	}
+	for _, test := range tests {
+		err := checkMatureBalance(test.bals, test.amount)
+		if test.immature {
+			if !errors.Is(errors.InsufficientBalance, err) {
+				t.Errorf("%s: expected InsufficientBalance error, got %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+	}
+}
+
+func TestValidateSendAmount(t *testing.T) {
+	tests := []struct {
+		amount int64
+		valid  bool
+	}{
+		{0, false},
+		{-1, false},
+		{math.MinInt64, false},
+		{math.MaxInt64, false},
+		{dcrutil.MaxAmount + 1, false},
+		{1, true},
+		{dcrutil.MaxAmount, true},
+	}
+	for _, test := range tests {
+		err := validateSendAmount(test.amount)
+		if test.valid && err != nil {
+			t.Errorf("amount %d: unexpected error: %v", test.amount, err)
+		}
+		if !test.valid && !errors.Is(errors.Invalid, err) {
+			t.Errorf("amount %d: expected Invalid error, got %v", test.amount, err)
+		}
+	}
+}
+
+func isZeroed(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestZeroBytes(t *testing.T) {
+	b := []byte("private passphrase")
+	zeroBytes(b)
+	if !isZeroed(b) {
+		t.Errorf("slice not cleared: %v", b)
+	}
+	zeroBytes(nil)
+}
+
+func TestSpvSyncClearsPassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mobilewallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lw := NewLibWallet(dir, "bdb")
+	lw.InitLoader()
+
+	// No wallet is loaded, so the sync fails before the passphrase is
+	// used.  It must be cleared nonetheless.
+	privPass := []byte("private passphrase")
+	err = lw.SpvSync(nil, "", true, privPass)
+	if err == nil {
+		t.Fatal("expected an error syncing without a loaded wallet")
+	}
+	if !isZeroed(privPass) {
+		t.Errorf("passphrase not cleared: %v", privPass)
+	}
+}
+
+// newTestWallet creates a wallet in a temporary directory.  The returned
+// function closes the wallet and removes the directory.
+func newTestWallet(t *testing.T) (*LibWallet, func()) {
+	dir, err := ioutil.TempDir("", "mobilewallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lw := NewLibWallet(dir, "bdb")
+	lw.InitLoader()
+	seed, err := lw.GenerateSeed()
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	err = lw.CreateWallet("private passphrase", seed)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return lw, func() {
+		lw.CloseWallet()
+		os.RemoveAll(dir)
+	}
+}
+
+// testScanResponse records how a rescan ended.
+type testScanResponse struct {
+	ended  chan bool
+	errors chan int32
+}
+
+func newTestScanResponse() *testScanResponse {
+	return &testScanResponse{
+		ended:  make(chan bool, 1),
+		errors: make(chan int32, 1),
+	}
+}
+
+func (r *testScanResponse) OnScan(rescannedThrough int32, targetHeight int32) bool { return true }
+func (r *testScanResponse) OnEnd(height int32, cancelled bool)                     { r.ended <- cancelled }
+func (r *testScanResponse) OnError(code int32, message string)                     { r.errors <- code }
+
+func TestRediscoverAccountSPV(t *testing.T) {
+	lw, cleanup := newTestWallet(t)
+	defer cleanup()
+
+	// The syncer is only used as the wallet's network backend and is not
+	// run, so rescanning the genesis block needs no peers.
+	lp := lw.newLocalPeer(&net.TCPAddr{IP: net.ParseIP("::1"), Port: 0})
+	lw.setNetworkBackend(spv.NewSyncer(lw.wallet, lp))
+
+	const gapLimit = 30
+	response := newTestScanResponse()
+	err := lw.RediscoverAccount(0, gapLimit, 0, response)
+	if err != nil {
+		t.Fatalf("RediscoverAccount: %v", err)
+	}
+	select {
+	case <-response.ended:
+	case code := <-response.errors:
+		t.Fatalf("rescan failed with code %d", code)
+	case <-time.After(30 * time.Second):
+		t.Fatal("timed out waiting for the rescan")
+	}
+
+	extIndex, intIndex, err := lw.wallet.BIP0044BranchNextIndexes(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extIndex < gapLimit || intIndex < gapLimit {
+		t.Errorf("addresses not derived through the gap: external %d, internal %d",
+			extIndex, intIndex)
+	}
+}
@@ -4,6 +4,7 @@ type ConstructTxResponse struct {
 	EstimatedSignedSize       int32
 	TotalOutputAmount         int64
 	TotalPreviousOutputAmount int64
+	MinimumFee                int64
 	UnsignedTransaction       []byte
 }
 
@@ -17,16 +18,93 @@ type Balance struct {
 	UnConfirmed             int64
 }
 
+// TotalBalance separates the balance of the HD accounts, which can be
+// recovered from the seed, from the balance of the imported account, which
+// cannot.
+type TotalBalance struct {
+	Total             int64
+	Spendable         int64
+	HDTotal           int64
+	HDSpendable       int64
+	ImportedTotal     int64
+	ImportedSpendable int64
+}
+
+type NetworkParams struct {
+	Name                    string
+	HDPurpose               uint32
+	CoinType                uint32
+	LegacyCoinType          uint32
+	PubKeyHashAddrID        string
+	PubKeyHashEdwardsAddrID string
+	PubKeyHashSchnorrAddrID string
+	ScriptHashAddrID        string
+	PrivateKeyID            string
+	HDPrivateKeyID          string
+	HDPublicKeyID           string
+	DefaultPort             string
+	JSONRPCServerPort       string
+	GRPCServerPort          string
+}
+
 type Account struct {
 	Number           int32
 	Name             string
 	Balance          *Balance
+	TieredBalances   *[]TieredBalance
 	TotalBalance     int64
 	ExternalKeyCount int32
 	InternalKeyCount int32
 	ImportedKeyCount int32
 }
 
+// TieredBalance is the balance of an account when requiring a number of
+// confirmations.
+type TieredBalance struct {
+	RequiredConfirmations int32
+	Balance               Balance
+}
+
+// AccountExtendedKey describes an account's extended public key and the next
+// child index of its external and internal branches.
+type AccountExtendedKey struct {
+	Account        int32
+	ExtendedPubKey string
+	ExternalIndex  int32
+	InternalIndex  int32
+}
+
+// AccountWatchingInfo holds what a watching-only wallet on another device
+// needs to derive the same addresses as an account.
+type AccountWatchingInfo struct {
+	Network        string
+	AccountName    string
+	Account        int32
+	ExtendedPubKey string
+	ExternalIndex  int32
+	InternalIndex  int32
+}
+
+// AccountName identifies an account without its balances.
+type AccountName struct {
+	Number int32
+	Name   string
+}
+
+type CreatedAccount struct {
+	Number  int32
+	Name    string
+	Address string
+}
+
+type GapStatus struct {
+	Account                 int32
+	AccountName             string
+	UnusedExternalAddresses int32
+	UnusedInternalAddresses int32
+	AtGapLimit              bool
+}
+
 type Accounts struct {
 	Count              int
 	ErrorMessage       string
@@ -37,8 +115,34 @@ type Accounts struct {
 	CurrentBlockHeight int32
 }
 
+type ImportedKey struct {
+	Index   int32
+	Address string
+}
+
+type ImportKeyFailure struct {
+	Index        int32
+	ErrorMessage string
+}
+
+type ImportPrivateKeysResponse struct {
+	Imported []ImportedKey
+	Failures []ImportKeyFailure
+}
+
+type UnspentOutput struct {
+	TransactionHash string
+	OutputIndex     int32
+	Tree            int32
+	Amount          int64
+	Account         int32
+}
+
+// BlockScanResponse receives the progress of a rescan.  targetHeight is the
+// height the rescan ends at, as also reported by
+// SpvSyncResponse.OnRescanProgress.
 type BlockScanResponse interface {
-	OnScan(rescannedThrough int32) bool
+	OnScan(rescannedThrough int32, targetHeight int32) bool
 	OnEnd(height int32, cancelled bool)
 	OnError(code int32, message string)
 }
@@ -50,17 +154,19 @@ Direction
 2: Transfered
 */
 type Transaction struct {
-	Hash        string
-	Transaction []byte
-	Fee         int64
-	Timestamp   int64
-	Type        string
-	Amount      int64
-	Status      string
-	Height      int32
-	Direction   int32
-	Debits      *[]TransactionDebit
-	Credits     *[]TransactionCredit
+	Hash             string
+	Transaction      []byte
+	Fee              int64
+	Timestamp        int64
+	BlockTimestamp   int64
+	Type             string
+	Amount           int64
+	Status           string
+	Height           int32
+	Direction        int32
+	AffectedAccounts []AccountName
+	Debits           *[]TransactionDebit
+	Credits          *[]TransactionCredit
 }
 
 type TransactionDebit struct {
@@ -88,22 +194,147 @@ type GetTransactionsResponse interface {
 	OnResult(json string)
 }
 
+type StreamTransactionsResponse interface {
+	OnTransaction(transaction string)
+}
+
 type TransactionListener interface {
 	OnTransaction(transaction string)
 	OnTransactionConfirmed(hash string, height int32)
 	OnBlockAttached(height int32)
 }
 
+type ConfirmationListener interface {
+	OnConfirmed(hash string, confirmations int32)
+	OnReorganized(hash string)
+	OnError(err error)
+}
+
+type PublishTransactionListener interface {
+	OnTransactionPublished(hash string)
+	OnTransactionRejected(hash string, err error)
+}
+
+type NetworkBackendListener interface {
+	OnNetworkBackendConnected()
+	OnNetworkBackendDisconnected()
+}
+
 type BlockNotificationError interface {
 	OnBlockNotificationError(err error)
 }
 
+type AddressBalance struct {
+	Address        string
+	Balance        int64
+	UnspentOutputs int32
+}
+
+// JSONRPCBatchResult is the result of one command of a JSON-RPC batch.  Result
+// holds the JSON encoded result when ErrorMessage is empty.
+type JSONRPCBatchResult struct {
+	Result       string
+	ErrorMessage string
+}
+
+// TicketVoteEstimate is the expected time until a ticket votes.  Status is one
+// of "immature", "live", "expired", "voted" or "revoked".
+type TicketVoteEstimate struct {
+	TicketHash              string
+	Status                  string
+	Live                    bool
+	PoolSize                int32
+	BlocksUntilLive         int32
+	BlocksUntilExpiry       int32
+	ExpectedBlocksUntilVote int32
+	ExpectedTimeUntilVote   int64
+}
+
+// SyncStatus is a snapshot of the SPV sync progress.  Phase is one of
+// "idle", "headers", "cfilters", "discovery", "rescan" or "synced".
+// EstimatedSecondsRemaining is -1 when no estimate is available.
+type SyncStatus struct {
+	Syncing                   bool
+	Synced                    bool
+	Phase                     string
+	BestBlockHeight           int32
+	TargetHeight              int32
+	Percentage                int32
+	EstimatedSecondsRemaining int64
+	PeerCount                 int32
+	Rescanning                bool
+	RescanHeight              int32
+}
+
+type VoteVersion struct {
+	WalletVoteVersion       uint32
+	NetworkStakeVersion     uint32
+	LatestDeploymentVersion uint32
+	Compatible              bool
+}
+
+type CFilterMatch struct {
+	Height           int32
+	BlockHash        string
+	AddressesChecked int32
+	Matched          bool
+	MatchedAddresses []string
+}
+
+type StakeDifficulty struct {
+	Height          int32
+	Timestamp       int64
+	StakeDifficulty int64
+}
+
+type IntegrityReport struct {
+	AccountsChecked     int32
+	TransactionsChecked int32
+	Issues              []string
+}
+
+type SentTransaction struct {
+	Hash string
+	Fee  int64
+	Size int32
+}
+
+type PreparedTransaction struct {
+	Hash              string
+	SignedTransaction string
+	Fee               int64
+	TotalInput        int64
+	TotalOutput       int64
+	Change            int64
+	Outputs           []DecodedOutput
+}
+
+// BalanceChange is the effect of an unpublished transaction on an account.
+// ProjectedSpendable does not include change returned to the account, which
+// is not spendable until it is confirmed.
+type BalanceChange struct {
+	Account            int32
+	AccountName        string
+	Spent              int64
+	Received           int64
+	Net                int64
+	CurrentSpendable   int64
+	ProjectedSpendable int64
+}
+
+type ProjectedBalanceChange struct {
+	Fee      int64
+	Accounts []BalanceChange
+}
+
 type DecodedTransaction struct {
 	Hash     string
 	Type     string
 	Version  int32
 	LockTime int32
 	Expiry   int32
+	Size     int32
+	Fee      int64
 	Inputs   []DecodedInput
 	Outputs  []DecodedOutput
 }
@@ -118,10 +349,17 @@ type DecodedInput struct {
 }
 
 type DecodedOutput struct {
-	Index     int32
-	Value     int64
-	Version   int32
-	Addresses []string
+	Index      int32
+	Value      int64
+	Version    int32
+	Addresses  []string
+	ScriptType string
+}
+
+type AccountDiscoveryListener interface {
+	OnDiscoveryStarted()
+	OnDiscoveryFinished(accountCount int32)
+	OnDiscoveryError(err error)
 }
 
 type SpvSyncResponse interface {
@@ -129,8 +367,9 @@ type SpvSyncResponse interface {
 	OnPeerDisconnected(peerCount int32)
 	OnFetchMissingCFilters(fetchedCFiltersCount int32)
 	OnFetchedHeaders(peerInitialHeight, fetchedHeadersCount int32, lastHeaderTime int64)
+	OnFetchedHeadersProgress(progress int32)
 	OnDiscoveredAddresses(finished bool)
-	OnRescanProgress(rescannedThrough int32)
+	OnRescanProgress(rescannedThrough int32, targetHeight int32)
 	OnSynced(synced bool)
 	/*
 	* Handled Error Codes
@@ -0,0 +1,85 @@
+package mobilewallet
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/decred/dcrwallet/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted seeds are serialized as a version byte followed by the scrypt
+// salt, the secretbox nonce and the sealed seed.
+const (
+	encryptedSeedVersion = 1
+	seedSaltSize         = 16
+	seedNonceSize        = 24
+	seedKeySize          = 32
+
+	// scrypt parameters used to derive the encryption key from the
+	// export passphrase.
+	seedScryptN = 32768
+	seedScryptR = 8
+	seedScryptP = 1
+)
+
+func deriveSeedKey(passphrase, salt []byte) (*[seedKeySize]byte, error) {
+	derived, err := scrypt.Key(passphrase, salt, seedScryptN, seedScryptR, seedScryptP, seedKeySize)
+	if err != nil {
+		return nil, err
+	}
+	var key [seedKeySize]byte
+	copy(key[:], derived)
+	zeroBytes(derived)
+	return &key, nil
+}
+
+// encryptSeed seals seed with a key derived from passphrase.
+func encryptSeed(seed, passphrase []byte) ([]byte, error) {
+	var salt [seedSaltSize]byte
+	var nonce [seedNonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, salt[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+	key, err := deriveSeedKey(passphrase, salt[:])
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(key[:])
+
+	out := make([]byte, 0, 1+seedSaltSize+seedNonceSize+len(seed)+secretbox.Overhead)
+	out = append(out, encryptedSeedVersion)
+	out = append(out, salt[:]...)
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, seed, &nonce, key), nil
+}
+
+// decryptSeed opens a seed sealed by encryptSeed.
+func decryptSeed(encrypted, passphrase []byte) ([]byte, error) {
+	if len(encrypted) < 1+seedSaltSize+seedNonceSize+secretbox.Overhead {
+		return nil, errors.E(errors.Encoding, "encrypted seed is too short")
+	}
+	if encrypted[0] != encryptedSeedVersion {
+		return nil, errors.E(errors.Encoding, "unknown encrypted seed version")
+	}
+	salt := encrypted[1 : 1+seedSaltSize]
+	var nonce [seedNonceSize]byte
+	copy(nonce[:], encrypted[1+seedSaltSize:])
+	sealed := encrypted[1+seedSaltSize+seedNonceSize:]
+
+	key, err := deriveSeedKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(key[:])
+
+	seed, ok := secretbox.Open(nil, sealed, &nonce, key)
+	if !ok {
+		return nil, errors.E(errors.Passphrase, "invalid export passphrase")
+	}
+	return seed, nil
+}